@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -115,80 +118,51 @@ const (
 	// ExitCodeQuotaExceeded quota exceeded
 	ExitCodeQuotaExceeded ExitCode = 87
 
+	// ===== SHELL CODES (126-127) - POSIX shell convention =====
+	// ExitCodeCannotExecute command found but could not be executed
+	ExitCodeCannotExecute ExitCode = 126
+
+	// ExitCodeCommandNotFound command not found
+	ExitCodeCommandNotFound ExitCode = 127
+
 	// ===== SYSTEM/SIGNAL CODES (128+) =====
+	// Any 128+n code denotes termination by signal n, see FromSignal and ExitCode.Signal
+
+	// ExitCodeHangup controlling terminal closed (SIGHUP)
+	ExitCodeHangup ExitCode = 129
+
 	// ExitCodeInterrupted process interrupted by user (Ctrl+C, SIGINT)
 	ExitCodeInterrupted ExitCode = 130
 
+	// ExitCodeQuit process quit by user (Ctrl+\, SIGQUIT)
+	ExitCodeQuit ExitCode = 131
+
+	// ExitCodeKilled process killed (SIGKILL)
+	ExitCodeKilled ExitCode = 137
+
+	// ExitCodeBrokenPipe write to a closed pipe (SIGPIPE)
+	ExitCodeBrokenPipe ExitCode = 141
+
 	// ExitCodeTerminated process terminated by system (SIGTERM)
 	ExitCodeTerminated ExitCode = 143
 )
 
 // String returns a human-readable description of the exit code
 func (c ExitCode) String() string {
-	switch c {
-	case ExitCodeSuccess:
-		return "Success"
-	case ExitCodeErrorInternal:
-		return "Internal error"
-	case ExitCodeInvalidArgument:
-		return "Invalid argument"
-	case ExitCodeCmdUsage:
-		return "Command usage error"
-	case ExitCodeDataError:
-		return "Data format error"
-	case ExitCodeNoInput:
-		return "Input file not found"
-	case ExitCodeNoUser:
-		return "User not found"
-	case ExitCodeNoHost:
-		return "Host not found"
-	case ExitCodeUnavailable:
-		return "Service unavailable"
-	case ExitCodeSoftware:
-		return "Internal software error"
-	case ExitCodeOSError:
-		return "Operating system error"
-	case ExitCodeOSFile:
-		return "System file error"
-	case ExitCodeCantCreate:
-		return "Cannot create output file"
-	case ExitCodeIOError:
-		return "I/O error"
-	case ExitCodeTempFail:
-		return "Temporary failure"
-	case ExitCodeProtocol:
-		return "Protocol error"
-	case ExitCodeNoPermission:
-		return "Permission denied"
-	case ExitCodeConfig:
-		return "Configuration error"
-	case ExitCodeAuthRequired:
-		return "Authentication required"
-	case ExitCodeAuthFailed:
-		return "Authentication failed"
-	case ExitCodeForbidden:
-		return "Forbidden"
-	case ExitCodeNotFound:
-		return "Not found"
-	case ExitCodeConflict:
-		return "Conflict"
-	case ExitCodeValidation:
-		return "Validation error"
-	case ExitCodeRateLimit:
-		return "Rate limit exceeded"
-	case ExitCodeQuotaExceeded:
-		return "Quota exceeded"
-	case ExitCodeInterrupted:
-		return "Interrupted by user"
-	case ExitCodeTerminated:
-		return "Terminated by system"
-	default:
-		return fmt.Sprintf("Unknown exit code: %d", int(c))
+	if info, ok := LookupCode(c); ok {
+		return info.Name
+	}
+	if sig, ok := c.Signal(); ok {
+		return fmt.Sprintf("Terminated by signal %d (%v)", int(c)-signalBase, sig)
 	}
+	return fmt.Sprintf("Unknown exit code: %d", int(c))
 }
 
 // Category returns the category of the exit code
 func (c ExitCode) Category() Category {
+	if info, ok := LookupCode(c); ok && info.Category != "" {
+		return info.Category
+	}
 	switch {
 	case c == 0:
 		return CategorySuccess
@@ -207,31 +181,14 @@ func (c ExitCode) Category() Category {
 
 // IsRetriable indicates whether the operation should be retried for this code
 func (c ExitCode) IsRetriable() bool {
-	switch c {
-	case ExitCodeTempFail, ExitCodeUnavailable, ExitCodeIOError, ExitCodeRateLimit:
-		return true
-	default:
-		return false
-	}
+	info, _ := LookupCode(c)
+	return info.Retriable
 }
 
 // IsUserError indicates whether the error is a result of incorrect user actions
 func (c ExitCode) IsUserError() bool {
-	switch c {
-	case ExitCodeInvalidArgument, // == ExitCodeUsageError
-		ExitCodeCmdUsage,
-		ExitCodeDataError,
-		ExitCodeNoInput,
-		ExitCodeNoUser,
-		ExitCodeNoHost,
-		ExitCodeNoPermission,
-		ExitCodeConfig,
-		ExitCodeNotFound,
-		ExitCodeValidation:
-		return true
-	default:
-		return false
-	}
+	info, _ := LookupCode(c)
+	return info.UserError
 }
 
 // MarshalText implements encoding.TextMarshaler (stable numeric string)
@@ -292,6 +249,13 @@ type ExitError struct {
 	Code    ExitCode
 	Message string
 	Cause   error
+
+	// fields holds structured context attached with WithField
+	fields map[string]any
+	// stack is the creation stack, captured when enabled (see SetCaptureStacks)
+	stack StackTrace
+	// hints are remediation suggestions attached with WithHint
+	hints []string
 }
 
 func (e *ExitError) Error() string {
@@ -310,16 +274,26 @@ func (e *ExitError) Unwrap() error {
 
 // NewExitError creates a new error with an exit code
 func NewExitError(code ExitCode, message string, cause error) *ExitError {
-	return &ExitError{
+	return newExitError(1, code, message, cause)
+}
+
+// newExitError creates an ExitError, capturing the stack of the caller skip
+// levels up when stack capture is enabled
+func newExitError(skip int, code ExitCode, message string, cause error) *ExitError {
+	e := &ExitError{
 		Code:    code,
 		Message: message,
 		Cause:   cause,
 	}
+	if captureStacks.Load() {
+		e.stack = callers(skip)
+	}
+	return e
 }
 
 // Newf creates a new error with a formatted message and code
 func Newf(code ExitCode, format string, args ...any) *ExitError {
-	return NewExitError(code, fmt.Sprintf(format, args...), nil)
+	return newExitError(1, code, fmt.Sprintf(format, args...), nil)
 }
 
 // WithCode wraps an existing error and assigns it a code
@@ -328,141 +302,266 @@ func WithCode(err error, code ExitCode) *ExitError {
 		return nil
 	}
 	// Preserve original error text in Message and the error itself in Cause
-	return NewExitError(code, err.Error(), err)
+	return newExitError(1, code, err.Error(), err)
+}
+
+// WithField attaches a key/value pair (resource ID, file path, request ID, ...)
+// to the error and returns the error for chaining
+func (e *ExitError) WithField(key string, value any) *ExitError {
+	if e == nil {
+		return nil
+	}
+	if e.fields == nil {
+		e.fields = make(map[string]any)
+	}
+	e.fields[key] = value
+	return e
+}
+
+// WithFields attaches all key/value pairs to the error and returns the error for chaining
+func (e *ExitError) WithFields(fields map[string]any) *ExitError {
+	for k, v := range fields {
+		e = e.WithField(k, v)
+	}
+	return e
+}
+
+// Field returns the value attached under key
+func (e *ExitError) Field(key string) (any, bool) {
+	if e == nil {
+		return nil, false
+	}
+	v, ok := e.fields[key]
+	return v, ok
+}
+
+// Fields returns a copy of the attached key/value pairs
+func (e *ExitError) Fields() map[string]any {
+	if e == nil || len(e.fields) == 0 {
+		return nil
+	}
+	fields := make(map[string]any, len(e.fields))
+	for k, v := range e.fields {
+		fields[k] = v
+	}
+	return fields
+}
+
+// WithHint attaches a remediation suggestion shown to the user (see
+// Renderer) and returns the error for chaining
+func (e *ExitError) WithHint(hint string) *ExitError {
+	if e == nil {
+		return nil
+	}
+	e.hints = append(e.hints, hint)
+	return e
+}
+
+// Hints returns a copy of the attached hints in the order they were added
+func (e *ExitError) Hints() []string {
+	if e == nil || len(e.hints) == 0 {
+		return nil
+	}
+	return append([]string(nil), e.hints...)
+}
+
+// ExitErrorJSONVersion is the version of the ExitError JSON wire format.
+// The format is stable: fields are only added, never renamed or removed,
+// and the version is bumped if their meaning ever changes.
+const ExitErrorJSONVersion = 1
+
+// exitErrorJSON is the ExitError wire format
+type exitErrorJSON struct {
+	Version  int            `json:"version"`
+	Code     int            `json:"code"`
+	Name     string         `json:"name"`
+	Category Category       `json:"category"`
+	Message  string         `json:"message"`
+	Cause    string         `json:"cause,omitempty"`
+	Fields   map[string]any `json:"fields,omitempty"`
+	Hints    []string       `json:"hints,omitempty"`
+	// Stack is only written when SetVerboseJSON is enabled
+	Stack []stackFrameJSON `json:"stack,omitempty"`
 }
 
 // MarshalJSON implements json.Marshaler for structured logging/transport
 func (e *ExitError) MarshalJSON() ([]byte, error) {
-	type alias struct {
-		Code     int      `json:"code"`
-		Name     string   `json:"name"`
-		Category Category `json:"category"`
-		Message  string   `json:"message"`
-		Cause    string   `json:"cause,omitempty"`
-	}
+	return json.Marshal(e.wire())
+}
+
+// wire returns the wire representation of e
+func (e *ExitError) wire() exitErrorJSON {
 	var cause string
 	if e.Cause != nil {
 		cause = e.Cause.Error()
 	}
-	return json.Marshal(alias{
+	w := exitErrorJSON{
+		Version:  ExitErrorJSONVersion,
 		Code:     int(e.Code),
 		Name:     e.Code.String(),
 		Category: e.Code.Category(),
 		Message:  e.Error(),
 		Cause:    cause,
-	})
+		Fields:   e.fields,
+		Hints:    e.hints,
+	}
+	if verboseJSON.Load() {
+		w.Stack = e.stack.json()
+	}
+	return w
 }
 
-// ResolveExitCode determines the exit code based on an error
-func ResolveExitCode(err error) ExitCode {
-	if err == nil {
-		return ExitCodeSuccess
+// UnmarshalJSON implements json.Unmarshaler, so a parent process can rebuild
+// the error reported by a child. The code, message, fields and hints are
+// restored; the cause becomes an opaque *ExitError carrying the cause text and
+// the same code, so it still resolves through ResolveExitCode. A stack is not
+// restored. Output without a version field (version 0) is accepted.
+func (e *ExitError) UnmarshalJSON(data []byte) error {
+	var w exitErrorJSON
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	if w.Version > ExitErrorJSONVersion {
+		return fmt.Errorf("unsupported exit error version: %d", w.Version)
+	}
+	*e = ExitError{
+		Code:    ExitCode(w.Code),
+		Message: w.Message,
+		fields:  w.Fields,
+		hints:   w.Hints,
 	}
+	if w.Cause != "" {
+		e.Cause = &ExitError{Code: e.Code, Message: w.Cause}
+	}
+	return nil
+}
+
+// LogValue implements slog.LogValuer so log records carry the code and fields
+func (e *ExitError) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.Int("code", int(e.Code)),
+		slog.String("name", e.Code.String()),
+		slog.String("category", string(e.Code.Category())),
+		slog.String("message", e.Error()),
+	}
+	if e.Cause != nil {
+		attrs = append(attrs, slog.String("cause", e.Cause.Error()))
+	}
+	if len(e.fields) > 0 {
+		keys := make([]string, 0, len(e.fields))
+		for k := range e.fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fields := make([]any, 0, len(keys))
+		for _, k := range keys {
+			fields = append(fields, slog.Any(k, e.fields[k]))
+		}
+		attrs = append(attrs, slog.Group("fields", fields...))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// ResolveExitCode determines the exit code based on an error.
+// The error is passed through the resolver chain (see Resolvers); the first
+// resolver that recognizes it decides the code, otherwise the result is
+// ExitCodeErrorInternal. Joined errors are resolved per branch according to
+// the ResolutionPolicy. Use ExplainExitCode to see which rule matched.
+func ResolveExitCode(err error) ExitCode {
+	return ExplainExitCode(err).Code
+}
 
-	// Check for ExitError
+// resolveExitError returns the code of an ExitError in the chain
+func resolveExitError(err error) (Resolution, bool) {
 	var exitErr *ExitError
 	if errors.As(err, &exitErr) {
-		return exitErr.Code
+		return Resolution{Code: exitErr.Code, Rule: "ExitError", Match: exitErr}, true
 	}
+	return Resolution{}, false
+}
 
-	// Mapping of common standard library errors
+// resolveContext maps context cancellation and deadlines
+func resolveContext(err error) (Resolution, bool) {
 	if errors.Is(err, context.Canceled) {
-		return ExitCodeInterrupted
+		return matchSentinel(err, context.Canceled, ExitCodeInterrupted, "context.Canceled"), true
 	}
 	if errors.Is(err, context.DeadlineExceeded) {
-		return ExitCodeTempFail
+		return matchSentinel(err, context.DeadlineExceeded, ExitCodeTempFail, "context.DeadlineExceeded"), true
 	}
-	// os errors
+	return Resolution{}, false
+}
+
+// resolveOS maps os errors
+func resolveOS(err error) (Resolution, bool) {
 	if os.IsNotExist(err) {
 		// For local resources return NoInput (sysexits: 66)
-		return ExitCodeNoInput
+		return Resolution{Code: ExitCodeNoInput, Rule: "os.IsNotExist", Match: err}, true
 	}
 	if os.IsPermission(err) {
-		return ExitCodeNoPermission
-	}
-	// net errors
-	var ne net.Error
-	if errors.As(err, &ne) {
-		if ne.Timeout() {
-			return ExitCodeTempFail
-		}
-		// If error is marked as temporary - also TempFail
-		if te, ok := any(ne).(interface{ Temporary() bool }); ok && te.Temporary() {
-			return ExitCodeTempFail
-		}
-		// Otherwise consider service unavailable
-		return ExitCodeUnavailable
+		return Resolution{Code: ExitCodeNoPermission, Rule: "os.IsPermission", Match: err}, true
 	}
+	return Resolution{}, false
+}
 
-	// Check predefined errors (compatibility with existing code)
-	switch {
-	case errors.Is(err, ErrInternal):
-		return ExitCodeErrorInternal
-	case errors.Is(err, ErrInvalid):
-		return ExitCodeInvalidArgument
-	// New checks
-	case errors.Is(err, ErrUsage):
-		return ExitCodeUsageError
-	case errors.Is(err, ErrDataFormat):
-		return ExitCodeDataError
-	case errors.Is(err, ErrNotFound):
-		return ExitCodeNotFound
-	case errors.Is(err, ErrNoPermission):
-		return ExitCodeNoPermission
-	case errors.Is(err, ErrConfig):
-		return ExitCodeConfig
-	case errors.Is(err, ErrAuth):
-		return ExitCodeAuthFailed
-	case errors.Is(err, ErrForbidden):
-		return ExitCodeForbidden
-	case errors.Is(err, ErrValidation):
-		return ExitCodeValidation
-	case errors.Is(err, ErrIO):
-		return ExitCodeIOError
-	case errors.Is(err, ErrUnavailable):
-		return ExitCodeUnavailable
-	case errors.Is(err, ErrTempFail):
-		return ExitCodeTempFail
-	default:
-		return ExitCodeErrorInternal
+// resolveNet maps net errors
+func resolveNet(err error) (Resolution, bool) {
+	// *url.Error only relays Timeout and Temporary of the error it wraps,
+	// so it is skipped and the wrapped error decides
+	match := findLink(err, func(e error) bool {
+		_, isNet := e.(net.Error)
+		_, isURL := e.(*url.Error)
+		return isNet && !isURL
+	})
+	if match == nil {
+		return Resolution{}, false
+	}
+	ne := match.(net.Error)
+	if ne.Timeout() {
+		return Resolution{Code: ExitCodeTempFail, Rule: "net.Error timeout", Match: ne}, true
 	}
+	// If error is marked as temporary - also TempFail
+	if te, ok := any(ne).(interface{ Temporary() bool }); ok && te.Temporary() {
+		return Resolution{Code: ExitCodeTempFail, Rule: "net.Error temporary", Match: ne}, true
+	}
+	// Otherwise consider service unavailable
+	return Resolution{Code: ExitCodeUnavailable, Rule: "net.Error", Match: ne}, true
 }
 
 // ===== HELPER FUNCTIONS =====
 
 // UsageError creates an incorrect usage error
 func UsageError(message string) *ExitError {
-	return NewExitError(ExitCodeUsageError, message, ErrUsage)
+	return newExitError(1, ExitCodeUsageError, message, ErrUsage)
 }
 
 // ValidationError creates a validation error
 func ValidationError(message string) *ExitError {
-	return NewExitError(ExitCodeValidation, message, ErrValidation)
+	return newExitError(1, ExitCodeValidation, message, ErrValidation)
 }
 
 // ConfigError creates a configuration error
 func ConfigError(message string) *ExitError {
-	return NewExitError(ExitCodeConfig, message, ErrConfig)
+	return newExitError(1, ExitCodeConfig, message, ErrConfig)
 }
 
 // NotFoundError creates a "not found" error
 func NotFoundError(resource string) *ExitError {
-	return NewExitError(ExitCodeNotFound, fmt.Sprintf("%s not found", resource), ErrNotFound)
+	return newExitError(1, ExitCodeNotFound, fmt.Sprintf("%s not found", resource), ErrNotFound)
 }
 
 // PermissionError creates a permission denied error
 func PermissionError(action string) *ExitError {
-	return NewExitError(ExitCodeNoPermission, fmt.Sprintf("permission denied: %s", action), ErrNoPermission)
+	return newExitError(1, ExitCodeNoPermission, fmt.Sprintf("permission denied: %s", action), ErrNoPermission)
 }
 
 // AuthError creates an authentication error
 func AuthError(message string) *ExitError {
-	return NewExitError(ExitCodeAuthFailed, message, ErrAuth)
+	return newExitError(1, ExitCodeAuthFailed, message, ErrAuth)
 }
 
 // TempFailError creates a temporary failure error
 func TempFailError(message string) *ExitError {
-	return NewExitError(ExitCodeTempFail, message, ErrTempFail)
+	return newExitError(1, ExitCodeTempFail, message, ErrTempFail)
 }
 
 // OSExitCode returns an integer code for use with os.Exit
@@ -511,26 +610,8 @@ func FromHTTPStatus(status int) ExitCode {
 
 // ToHTTPStatus maps ExitCode to recommended HTTP status
 func ToHTTPStatus(code ExitCode) int {
-	switch code {
-	case ExitCodeSuccess:
-		return 200
-	case ExitCodeInvalidArgument, ExitCodeCmdUsage, ExitCodeDataError, ExitCodeValidation:
-		return 400
-	case ExitCodeAuthRequired, ExitCodeAuthFailed:
-		return 401
-	case ExitCodeForbidden, ExitCodeNoPermission:
-		return 403
-	case ExitCodeNotFound, ExitCodeNoInput:
-		return 404
-	case ExitCodeConflict:
-		return 409
-	case ExitCodeRateLimit, ExitCodeQuotaExceeded:
-		return 429
-	case ExitCodeUnavailable, ExitCodeTempFail:
-		return 503
-	case ExitCodeSoftware, ExitCodeOSError, ExitCodeIOError, ExitCodeErrorInternal:
-		return 500
-	default:
-		return 500
+	if info, ok := LookupCode(code); ok && info.HTTPStatus != 0 {
+		return info.HTTPStatus
 	}
+	return 500
 }
@@ -1,9 +1,11 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"log/slog"
 	"testing"
 )
 
@@ -375,6 +377,116 @@ func TestExitError_JSONMarshaling(t *testing.T) {
 	}
 }
 
+func TestExitError_Fields(t *testing.T) {
+	err := NotFoundError("bucket").
+		WithField("bucket", "logs-eu").
+		WithFields(map[string]any{"request_id": "req-42", "attempt": 3})
+
+	if v, ok := err.Field("bucket"); !ok || v != "logs-eu" {
+		t.Fatalf("Field(bucket) = %v, %v", v, ok)
+	}
+	fields := err.Fields()
+	if len(fields) != 3 {
+		t.Fatalf("Fields() = %v, want 3 entries", fields)
+	}
+	fields["bucket"] = "changed"
+	if v, _ := err.Field("bucket"); v != "logs-eu" {
+		t.Fatal("Fields() should return a copy")
+	}
+	if NewExitError(ExitCodeConfig, "x", nil).Fields() != nil {
+		t.Fatal("Fields() without fields should be nil")
+	}
+
+	var nilErr *ExitError
+	if nilErr.WithField("k", "v") != nil {
+		t.Fatal("WithField on nil should return nil")
+	}
+	if nilErr.WithFields(map[string]any{"k": "v"}) != nil {
+		t.Fatal("WithFields on nil should return nil")
+	}
+	if v, ok := nilErr.Field("k"); ok || v != nil {
+		t.Fatalf("Field on nil = %v, %v", v, ok)
+	}
+	if nilErr.Fields() != nil {
+		t.Fatal("Fields on nil should be nil")
+	}
+}
+
+func TestExitError_JSONFields(t *testing.T) {
+	err := NewExitError(ExitCodeConflict, "already exists", nil).WithField("resource_id", "vol-1")
+	data, mErr := json.Marshal(err)
+	if mErr != nil {
+		t.Fatalf("json.Marshal error: %v", mErr)
+	}
+	want := `{"version":1,"code":84,"name":"Conflict","category":"cli_extended","message":"already exists","fields":{"resource_id":"vol-1"}}`
+	if string(data) != want {
+		t.Fatalf("json = %s, want %s", data, want)
+	}
+}
+
+func TestExitError_LogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	err := ConfigError("missing key").WithField("path", "/etc/app.yaml")
+	logger.Error("failed", "err", err)
+
+	want := `level=ERROR msg=failed err.code=78 err.name="Configuration error" err.category=user_error err.message="missing key" err.cause="configuration error" err.fields.path=/etc/app.yaml` + "\n"
+	if buf.String() != want {
+		t.Fatalf("log = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestExitError_JSONRoundTrip(t *testing.T) {
+	orig := WithCode(errors.New("connection reset"), ExitCodeUnavailable).WithField("host", "db-1")
+	orig.Message = "database unavailable"
+	data, mErr := json.Marshal(orig)
+	if mErr != nil {
+		t.Fatalf("json.Marshal error: %v", mErr)
+	}
+
+	var decoded ExitError
+	if uErr := json.Unmarshal(data, &decoded); uErr != nil {
+		t.Fatalf("json.Unmarshal error: %v", uErr)
+	}
+	if decoded.Code != ExitCodeUnavailable || decoded.Error() != "database unavailable" {
+		t.Fatalf("decoded = %d %q", decoded.Code, decoded.Error())
+	}
+	if v, _ := decoded.Field("host"); v != "db-1" {
+		t.Fatalf("decoded field host = %v", v)
+	}
+	if decoded.Cause == nil || decoded.Cause.Error() != "connection reset" {
+		t.Fatalf("decoded cause = %v", decoded.Cause)
+	}
+	if got := ResolveExitCode(decoded.Cause); got != ExitCodeUnavailable {
+		t.Fatalf("ResolveExitCode(cause) = %d, want %d", got, ExitCodeUnavailable)
+	}
+
+	again, mErr := json.Marshal(&decoded)
+	if mErr != nil || string(again) != string(data) {
+		t.Fatalf("re-encoded = %s, want %s", again, data)
+	}
+}
+
+func TestExitError_UnmarshalJSON_Versions(t *testing.T) {
+	var e ExitError
+	if err := json.Unmarshal([]byte(`{"code":83,"name":"Not found","message":"gone"}`), &e); err != nil {
+		t.Fatalf("unversioned input returned error: %v", err)
+	}
+	if e.Code != ExitCodeNotFound || e.Cause != nil {
+		t.Fatalf("decoded = %+v", e)
+	}
+	if err := json.Unmarshal([]byte(`{"version":99,"code":1}`), &e); err == nil {
+		t.Fatal("future version should return error")
+	}
+}
+
 func TestExitCode_UnmarshalText(t *testing.T) {
 	var c ExitCode
 	// empty -> success
@@ -492,3 +604,31 @@ func TestIntegrationExample(t *testing.T) {
 		})
 	}
 }
+
+func TestExitError_Hints(t *testing.T) {
+	e := ConfigError("missing endpoint").WithHint("pass --endpoint").WithHint("or set CLI_ENDPOINT")
+	hints := e.Hints()
+	if len(hints) != 2 || hints[0] != "pass --endpoint" || hints[1] != "or set CLI_ENDPOINT" {
+		t.Errorf("Hints() = %q", hints)
+	}
+	hints[0] = "changed"
+	if e.Hints()[0] != "pass --endpoint" {
+		t.Error("Hints() should return a copy")
+	}
+	var nilErr *ExitError
+	if nilErr.Hints() != nil {
+		t.Error("Hints() on nil should be nil")
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ExitError
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got := decoded.Hints(); len(got) != 2 || got[1] != "or set CLI_ENDPOINT" {
+		t.Errorf("decoded hints = %q", got)
+	}
+}
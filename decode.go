@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// Position locates an error in decoded input. Zero fields are unknown.
+type Position struct {
+	File string
+	// Line and Column are 1-based; Column counts bytes
+	Line   int
+	Column int
+	// Offset is the byte offset of the offending byte from the start of the input
+	Offset int64
+}
+
+// String formats the position as "file:line:column", dropping unknown
+// parts, or as "file:offset N" when only the offset is known
+func (p Position) String() string {
+	s := p.File
+	switch {
+	case p.Line > 0:
+		if s != "" {
+			s += ":"
+		}
+		s += strconv.Itoa(p.Line)
+		if p.Column > 0 {
+			s += ":" + strconv.Itoa(p.Column)
+		}
+	case p.Offset > 0:
+		if s != "" {
+			s += ":"
+		}
+		s += "offset " + strconv.FormatInt(p.Offset, 10)
+	}
+	return s
+}
+
+// ErrorPosition returns the position carried by a decode error in err's
+// chain: *json.SyntaxError and *json.UnmarshalTypeError (offset),
+// *xml.SyntaxError (line) and *csv.ParseError (line and column).
+// The json offsets, which count the bytes read, are converted to the offset
+// of the last byte read.
+func ErrorPosition(err error) (Position, bool) {
+	var (
+		jsonSyntax *json.SyntaxError
+		jsonType   *json.UnmarshalTypeError
+		xmlSyntax  *xml.SyntaxError
+		csvParse   *csv.ParseError
+	)
+	switch {
+	case errors.As(err, &jsonSyntax):
+		return Position{Offset: max(jsonSyntax.Offset-1, 0)}, true
+	case errors.As(err, &jsonType):
+		return Position{Offset: max(jsonType.Offset-1, 0)}, true
+	case errors.As(err, &xmlSyntax):
+		return Position{Line: xmlSyntax.Line}, true
+	case errors.As(err, &csvParse):
+		return Position{Line: csvParse.Line, Column: csvParse.Column}, true
+	}
+	return Position{}, false
+}
+
+// OffsetPosition returns the line and column of the byte at offset in src
+func OffsetPosition(src []byte, offset int64) Position {
+	offset = min(max(offset, 0), int64(len(src)))
+	before := src[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return Position{Line: line, Column: column, Offset: offset}
+}
+
+// DecodeError wraps an error from decoding src, read from file, into an
+// *ExitError with a "file:line:column: message" message and the file, line,
+// column and offset fields. Positions reported only as offsets are converted
+// using src, which may be nil. The code is ExitCodeDataError unless err
+// resolves to a more specific code than a decode failure (an I/O error, say).
+func DecodeError(file string, src []byte, err error) *ExitError {
+	if err == nil {
+		return nil
+	}
+	pos, ok := ErrorPosition(err)
+	if ok && pos.Line == 0 && src != nil {
+		pos = OffsetPosition(src, pos.Offset)
+	}
+	pos.File = file
+
+	code := ResolveExitCode(err)
+	if code == ExitCodeErrorInternal || code == ExitCodeInvalidArgument {
+		code = ExitCodeDataError
+	}
+	message := decodeMessage(err)
+	if where := pos.String(); where != "" {
+		message = where + ": " + message
+	}
+
+	e := NewExitError(code, message, err)
+	if file != "" {
+		e.WithField("file", file)
+	}
+	if pos.Line > 0 {
+		e.WithField("line", pos.Line)
+	}
+	if pos.Column > 0 {
+		e.WithField("column", pos.Column)
+	}
+	if pos.Offset > 0 {
+		e.WithField("offset", pos.Offset)
+	}
+	return e
+}
+
+// decodeMessage returns the message of a decode error without the position
+// that xml and csv include in their error text
+func decodeMessage(err error) string {
+	var (
+		xmlSyntax *xml.SyntaxError
+		csvParse  *csv.ParseError
+	)
+	switch {
+	case errors.As(err, &xmlSyntax):
+		return xmlSyntax.Msg
+	case errors.As(err, &csvParse):
+		return csvParse.Err.Error()
+	}
+	return err.Error()
+}
+
+// resolveDecode maps errors from encoding/json, encoding/xml, encoding/csv
+// and strconv, and truncated input
+func resolveDecode(err error) (Resolution, bool) {
+	var (
+		jsonSyntax *json.SyntaxError
+		jsonType   *json.UnmarshalTypeError
+		xmlSyntax  *xml.SyntaxError
+		xmlErr     xml.UnmarshalError
+		csvParse   *csv.ParseError
+		numErr     *strconv.NumError
+	)
+	switch {
+	case errors.As(err, &jsonSyntax):
+		return Resolution{Code: ExitCodeDataError, Rule: "json.SyntaxError", Match: jsonSyntax}, true
+	case errors.As(err, &jsonType):
+		return Resolution{Code: ExitCodeDataError, Rule: "json.UnmarshalTypeError", Match: jsonType}, true
+	case errors.As(err, &xmlSyntax):
+		return Resolution{Code: ExitCodeDataError, Rule: "xml.SyntaxError", Match: xmlSyntax}, true
+	case errors.As(err, &xmlErr):
+		return Resolution{Code: ExitCodeDataError, Rule: "xml.UnmarshalError", Match: xmlErr}, true
+	case errors.As(err, &csvParse):
+		return Resolution{Code: ExitCodeDataError, Rule: "csv.ParseError", Match: csvParse}, true
+	case errors.As(err, &numErr):
+		return Resolution{Code: ExitCodeInvalidArgument, Rule: "strconv.NumError", Match: numErr}, true
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return matchSentinel(err, io.ErrUnexpectedEOF, ExitCodeDataError, "io.ErrUnexpectedEOF"), true
+	}
+	return Resolution{}, false
+}
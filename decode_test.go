@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestResolveExitCode_Decode(t *testing.T) {
+	var v struct{ Port int }
+	jsonSyntax := json.Unmarshal([]byte(`{"port": 80,}`), &v)
+	jsonType := json.Unmarshal([]byte(`{"port": "http"}`), &v)
+	xmlSyntax := xml.Unmarshal([]byte("<a>\n<b></a>"), new(struct{}))
+	_, csvErr := csv.NewReader(strings.NewReader("a,b\nc\n")).ReadAll()
+	_, numErr := strconv.Atoi("eighty")
+	truncated := json.NewDecoder(strings.NewReader(`{"port": 8`)).Decode(&v)
+
+	tests := []struct {
+		name string
+		err  error
+		want ExitCode
+		rule string
+	}{
+		{"json_syntax", jsonSyntax, ExitCodeDataError, "json.SyntaxError"},
+		{"json_type", fmt.Errorf("config: %w", jsonType), ExitCodeDataError, "json.UnmarshalTypeError"},
+		{"xml_syntax", xmlSyntax, ExitCodeDataError, "xml.SyntaxError"},
+		{"csv", csvErr, ExitCodeDataError, "csv.ParseError"},
+		{"strconv", numErr, ExitCodeInvalidArgument, "strconv.NumError"},
+		{"unexpected_eof", truncated, ExitCodeDataError, "io.ErrUnexpectedEOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := ExplainExitCode(tt.err)
+			if res.Code != tt.want || res.Rule != tt.rule {
+				t.Errorf("ExplainExitCode(%v) = %d by %q, want %d by %q", tt.err, res.Code, res.Rule, tt.want, tt.rule)
+			}
+		})
+	}
+}
+
+func TestOffsetPosition(t *testing.T) {
+	src := []byte("ab\ncde\n\nf")
+	tests := []struct {
+		offset       int64
+		line, column int
+	}{
+		{0, 1, 1},
+		{2, 1, 3},
+		{3, 2, 1},
+		{5, 2, 3},
+		{7, 3, 1},
+		{8, 4, 1},
+		{100, 4, 2},
+	}
+	for _, tt := range tests {
+		p := OffsetPosition(src, tt.offset)
+		if p.Line != tt.line || p.Column != tt.column {
+			t.Errorf("OffsetPosition(%d) = %d:%d, want %d:%d", tt.offset, p.Line, p.Column, tt.line, tt.column)
+		}
+	}
+}
+
+func TestPosition_String(t *testing.T) {
+	tests := []struct {
+		pos  Position
+		want string
+	}{
+		{Position{File: "config.json", Line: 12, Column: 5}, "config.json:12:5"},
+		{Position{File: "data.xml", Line: 3}, "data.xml:3"},
+		{Position{File: "blob.json", Offset: 341}, "blob.json:offset 341"},
+		{Position{Line: 2, Column: 1}, "2:1"},
+		{Position{File: "x"}, "x"},
+		{Position{}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.pos.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.pos, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeError_JSON(t *testing.T) {
+	src := []byte("{\n  \"port\": 80,\n  \"host\": ,\n}")
+	var v map[string]any
+	err := DecodeError("config.json", src, json.Unmarshal(src, &v))
+
+	if err.Code != ExitCodeDataError {
+		t.Errorf("code = %d, want %d", err.Code, ExitCodeDataError)
+	}
+	if want := "config.json:3:11: invalid character ',' looking for beginning of value"; err.Error() != want {
+		t.Errorf("message = %q, want %q", err.Error(), want)
+	}
+	for key, want := range map[string]any{"file": "config.json", "line": 3, "column": 11, "offset": int64(26)} {
+		if got, _ := err.Field(key); got != want {
+			t.Errorf("field %s = %v (%T), want %v", key, got, got, want)
+		}
+	}
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Error("json.SyntaxError not reachable through the chain")
+	}
+}
+
+func TestDecodeError_CSVAndXML(t *testing.T) {
+	_, csvErr := csv.NewReader(strings.NewReader("a,b\nc\n")).ReadAll()
+	err := DecodeError("rows.csv", nil, csvErr)
+	if want := "rows.csv:2:1: wrong number of fields"; err.Error() != want {
+		t.Errorf("csv message = %q, want %q", err.Error(), want)
+	}
+
+	xmlErr := xml.Unmarshal([]byte("<a>\n<b></a>"), new(struct{}))
+	err = DecodeError("feed.xml", nil, xmlErr)
+	if want := "feed.xml:2: element <b> closed by </a>"; err.Error() != want {
+		t.Errorf("xml message = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestDecodeError_KeepsSpecificCode(t *testing.T) {
+	if DecodeError("x.json", nil, nil) != nil {
+		t.Error("DecodeError(nil) should be nil")
+	}
+
+	_, numErr := strconv.Atoi("eighty")
+	if err := DecodeError("ports.txt", nil, numErr); err.Code != ExitCodeDataError {
+		t.Errorf("strconv code = %d, want %d", err.Code, ExitCodeDataError)
+	}
+	if err := DecodeError("", nil, io.ErrUnexpectedEOF); err.Code != ExitCodeDataError || err.Error() != "unexpected EOF" {
+		t.Errorf("DecodeError(ErrUnexpectedEOF) = %d %q", err.Code, err.Error())
+	}
+	if err := DecodeError("remote.json", nil, ErrIO); err.Code != ExitCodeIOError {
+		t.Errorf("I/O code = %d, want %d", err.Code, ExitCodeIOError)
+	}
+}
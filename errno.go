@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"errors"
+	"syscall"
+)
+
+// errnoCode is an entry of the platform errno table
+type errnoCode struct {
+	name string
+	code ExitCode
+}
+
+// resolveErrno maps the syscall.Errno inside *os.PathError, *os.SyscallError,
+// *net.OpError and similar wrappers using the platform errno table
+func resolveErrno(err error) (Resolution, bool) {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return Resolution{}, false
+	}
+	entry, ok := errnoCodes[errno]
+	if !ok {
+		return Resolution{}, false
+	}
+	return Resolution{Code: entry.code, Rule: "syscall.Errno " + entry.name, Match: errno}, true
+}
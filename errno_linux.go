@@ -0,0 +1,49 @@
+package cli
+
+import "syscall"
+
+// errnoCodes maps Linux errno values to exit codes
+var errnoCodes = map[syscall.Errno]errnoCode{
+	// Files
+	syscall.ENOENT:       {"ENOENT", ExitCodeNoInput},
+	syscall.ENOTDIR:      {"ENOTDIR", ExitCodeNoInput},
+	syscall.ELOOP:        {"ELOOP", ExitCodeNoInput},
+	syscall.EACCES:       {"EACCES", ExitCodeNoPermission},
+	syscall.EPERM:        {"EPERM", ExitCodeNoPermission},
+	syscall.EEXIST:       {"EEXIST", ExitCodeCantCreate},
+	syscall.EISDIR:       {"EISDIR", ExitCodeCantCreate},
+	syscall.ENOTEMPTY:    {"ENOTEMPTY", ExitCodeCantCreate},
+	syscall.EROFS:        {"EROFS", ExitCodeCantCreate},
+	syscall.ENOSPC:       {"ENOSPC", ExitCodeCantCreate},
+	syscall.EFBIG:        {"EFBIG", ExitCodeCantCreate},
+	syscall.EXDEV:        {"EXDEV", ExitCodeCantCreate},
+	syscall.EDQUOT:       {"EDQUOT", ExitCodeQuotaExceeded},
+	syscall.ENAMETOOLONG: {"ENAMETOOLONG", ExitCodeInvalidArgument},
+	syscall.EIO:          {"EIO", ExitCodeIOError},
+	syscall.EPIPE:        {"EPIPE", ExitCodeIOError},
+	syscall.ENXIO:        {"ENXIO", ExitCodeOSFile},
+	syscall.ENODEV:       {"ENODEV", ExitCodeOSFile},
+	syscall.ENOEXEC:      {"ENOEXEC", ExitCodeCannotExecute},
+
+	// Resources
+	syscall.EBUSY:   {"EBUSY", ExitCodeTempFail},
+	syscall.ETXTBSY: {"ETXTBSY", ExitCodeTempFail},
+	syscall.EAGAIN:  {"EAGAIN", ExitCodeTempFail},
+	syscall.EINTR:   {"EINTR", ExitCodeTempFail},
+	syscall.EMFILE:  {"EMFILE", ExitCodeOSError},
+	syscall.ENFILE:  {"ENFILE", ExitCodeOSError},
+	syscall.ENOMEM:  {"ENOMEM", ExitCodeOSError},
+
+	// Network
+	syscall.ECONNREFUSED:  {"ECONNREFUSED", ExitCodeUnavailable},
+	syscall.ECONNRESET:    {"ECONNRESET", ExitCodeUnavailable},
+	syscall.ECONNABORTED:  {"ECONNABORTED", ExitCodeUnavailable},
+	syscall.ENETRESET:     {"ENETRESET", ExitCodeUnavailable},
+	syscall.ETIMEDOUT:     {"ETIMEDOUT", ExitCodeTempFail},
+	syscall.EHOSTUNREACH:  {"EHOSTUNREACH", ExitCodeNoHost},
+	syscall.EHOSTDOWN:     {"EHOSTDOWN", ExitCodeNoHost},
+	syscall.ENETUNREACH:   {"ENETUNREACH", ExitCodeNoHost},
+	syscall.ENETDOWN:      {"ENETDOWN", ExitCodeNoHost},
+	syscall.EADDRINUSE:    {"EADDRINUSE", ExitCodeConflict},
+	syscall.EADDRNOTAVAIL: {"EADDRNOTAVAIL", ExitCodeConfig},
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestResolveExitCode_Errno(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ExitCode
+	}{
+		{"enospc", &os.PathError{Op: "write", Path: "/tmp/out", Err: syscall.ENOSPC}, ExitCodeCantCreate},
+		{"edquot", &os.PathError{Op: "write", Path: "/home/u/out", Err: syscall.EDQUOT}, ExitCodeQuotaExceeded},
+		{"erofs", &os.PathError{Op: "open", Path: "/usr/out", Err: syscall.EROFS}, ExitCodeCantCreate},
+		{"eexist", &os.LinkError{Op: "symlink", Old: "a", New: "b", Err: syscall.EEXIST}, ExitCodeCantCreate},
+		{"ebusy", os.NewSyscallError("umount", syscall.EBUSY), ExitCodeTempFail},
+		{"emfile", os.NewSyscallError("socket", syscall.EMFILE), ExitCodeOSError},
+		{"epipe", fmt.Errorf("sending: %w", os.NewSyscallError("write", syscall.EPIPE)), ExitCodeIOError},
+		{"econnrefused", &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, ExitCodeUnavailable},
+		{"ehostunreach", &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.EHOSTUNREACH)}, ExitCodeNoHost},
+		{"bare", syscall.EROFS, ExitCodeCantCreate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveExitCode(tt.err); got != tt.want {
+				t.Errorf("ResolveExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExplainExitCode_Errno(t *testing.T) {
+	err := fmt.Errorf("saving: %w", &os.PathError{Op: "write", Path: "/tmp/out", Err: syscall.ENOSPC})
+	res := ExplainExitCode(err)
+	if res.Rule != "syscall.Errno ENOSPC" || res.Match != syscall.ENOSPC {
+		t.Errorf("ExplainExitCode() = %v", res)
+	}
+
+	// os.IsNotExist keeps precedence for missing files
+	res = ExplainExitCode(&os.PathError{Op: "open", Path: "x", Err: syscall.ENOENT})
+	if res.Rule != "os.IsNotExist" {
+		t.Errorf("ExplainExitCode(ENOENT) rule = %q, want os.IsNotExist", res.Rule)
+	}
+}
+
+func TestErrnoCodes_Registered(t *testing.T) {
+	for errno, entry := range errnoCodes {
+		if _, ok := LookupCode(entry.code); !ok {
+			t.Errorf("%s maps to unregistered code %d", entry.name, entry.code)
+		}
+		if errno == 0 {
+			t.Errorf("%s has zero errno", entry.name)
+		}
+	}
+}
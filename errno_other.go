@@ -0,0 +1,9 @@
+//go:build !linux
+
+package cli
+
+import "syscall"
+
+// errnoCodes is empty on platforms without an errno table; os.IsNotExist,
+// os.IsPermission and the net resolver still apply
+var errnoCodes = map[syscall.Errno]errnoCode{}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"errors"
+	"io/fs"
+	"os/exec"
+	"syscall"
+)
+
+// resolveExec maps errors from running child processes.
+// A child that exited keeps its own status, a child killed by a signal maps
+// to 128+n, and failures to start follow the POSIX shell convention:
+// 127 when the command is not found and 126 when it cannot be executed.
+func resolveExec(err error) (Resolution, bool) {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			return Resolution{Code: FromSignal(ws.Signal()), Rule: "exec.ExitError signal", Match: exitErr}, true
+		}
+		if code := exitErr.ExitCode(); code >= 0 {
+			return Resolution{Code: ExitCode(code), Rule: "exec.ExitError", Match: exitErr}, true
+		}
+		return Resolution{}, false
+	}
+
+	// exec.LookPath failures and os.StartProcess failures
+	var execErr *exec.Error
+	var pathErr *fs.PathError
+	var match error
+	switch {
+	case errors.As(err, &execErr):
+		match = execErr
+	case errors.As(err, &pathErr) && pathErr.Op == "fork/exec":
+		match = pathErr
+	default:
+		return Resolution{}, false
+	}
+	switch {
+	case errors.Is(match, exec.ErrNotFound), errors.Is(match, fs.ErrNotExist):
+		return Resolution{Code: ExitCodeCommandNotFound, Rule: "exec command not found", Match: match}, true
+	case errors.Is(match, fs.ErrPermission), errors.Is(match, syscall.ENOEXEC):
+		return Resolution{Code: ExitCodeCannotExecute, Rule: "exec permission denied", Match: match}, true
+	default:
+		return Resolution{}, false
+	}
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func skipUnlessUnix(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("requires a POSIX shell")
+	}
+}
+
+func TestResolveExitCode_ExecExitStatus(t *testing.T) {
+	skipUnlessUnix(t)
+	err := exec.Command("sh", "-c", "exit 3").Run()
+	if got := ResolveExitCode(err); got != 3 {
+		t.Errorf("ResolveExitCode() = %d, want 3", got)
+	}
+
+	// Wrapped child errors keep the child's status
+	wrapped := fmt.Errorf("git fetch: %w", exec.Command("sh", "-c", "exit 65").Run())
+	if got := ResolveExitCode(wrapped); got != ExitCodeDataError {
+		t.Errorf("ResolveExitCode(wrapped) = %d, want %d", got, ExitCodeDataError)
+	}
+}
+
+func TestResolveExitCode_ExecSignal(t *testing.T) {
+	skipUnlessUnix(t)
+	err := exec.Command("sh", "-c", "kill -TERM $$").Run()
+	if got := ResolveExitCode(err); got != ExitCodeTerminated {
+		t.Errorf("ResolveExitCode() = %d, want %d", got, ExitCodeTerminated)
+	}
+}
+
+func TestResolveExitCode_ExecNotFound(t *testing.T) {
+	err := exec.Command("cli-test-command-that-does-not-exist").Run()
+	if got := ResolveExitCode(err); got != ExitCodeCommandNotFound {
+		t.Errorf("ResolveExitCode(lookup) = %d, want %d", got, ExitCodeCommandNotFound)
+	}
+
+	skipUnlessUnix(t)
+	err = exec.Command(filepath.Join(t.TempDir(), "missing")).Run()
+	if got := ResolveExitCode(err); got != ExitCodeCommandNotFound {
+		t.Errorf("ResolveExitCode(start) = %d, want %d", got, ExitCodeCommandNotFound)
+	}
+}
+
+func TestResolveExitCode_ExecPermission(t *testing.T) {
+	skipUnlessUnix(t)
+	path := filepath.Join(t.TempDir(), "script")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	err := exec.Command(path).Run()
+	if got := ResolveExitCode(err); got != ExitCodeCannotExecute {
+		t.Errorf("ResolveExitCode() = %d, want %d", got, ExitCodeCannotExecute)
+	}
+}
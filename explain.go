@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ExplainEnv is the environment variable that makes Run print the
+// resolution of the final error to stderr before exiting
+const ExplainEnv = "CLI_EXPLAIN_EXIT"
+
+// Resolution describes why ResolveExitCode chose a code
+type Resolution struct {
+	// Code is the resolved exit code
+	Code ExitCode
+	// Rule names the check that matched, e.g. "ExitError", "os.IsNotExist",
+	// "net.Error timeout", `sentinel "not found"` or "default"
+	Rule string
+	// Match is the link in the error chain the rule matched; nil for the default rule
+	Match error
+}
+
+func (r Resolution) String() string {
+	s := fmt.Sprintf("exit code %d (%s) by rule %s", int(r.Code), r.Code, r.Rule)
+	if r.Match != nil {
+		s += fmt.Sprintf(" matching %T: %v", r.Match, r.Match)
+	}
+	return s
+}
+
+// ExplainExitCode resolves err like ResolveExitCode and reports which rule
+// matched and which link of the error chain it matched. For joined errors
+// the resolution is that of the branch chosen by the ResolutionPolicy.
+func ExplainExitCode(err error) Resolution {
+	if err == nil {
+		return Resolution{Code: ExitCodeSuccess, Rule: "nil error"}
+	}
+	return explainTree(err, CurrentResolutionPolicy())
+}
+
+// explainChain returns the resolution of the first resolver that recognizes err
+func explainChain(err error) Resolution {
+	for _, r := range Resolvers() {
+		if res, ok := explain(r, err); ok {
+			return res
+		}
+	}
+	return Resolution{Code: ExitCodeErrorInternal, Rule: "default"}
+}
+
+// explain runs a single resolver. Resolvers that cannot explain themselves
+// are named after NamedResolver or their type, and the matched link is the
+// deepest one in the chain the resolver still maps to the same code.
+func explain(r Resolver, err error) (Resolution, bool) {
+	if ex, ok := r.(interface {
+		explain(err error) (Resolution, bool)
+	}); ok {
+		return ex.explain(err)
+	}
+	code, ok := r.Resolve(err)
+	if !ok {
+		return Resolution{}, false
+	}
+	name := fmt.Sprintf("%T", r)
+	if n, ok := r.(interface{ Name() string }); ok {
+		name = n.Name()
+	}
+	match := deepestLink(err, func(e error) bool {
+		c, ok := r.Resolve(e)
+		return ok && c == code
+	})
+	return Resolution{Code: code, Rule: name, Match: match}, true
+}
+
+// matchSentinel builds the resolution for an errors.Is match of target
+func matchSentinel(err, target error, code ExitCode, rule string) Resolution {
+	match := deepestLink(err, func(e error) bool { return errors.Is(e, target) })
+	return Resolution{Code: code, Rule: rule, Match: match}
+}
+
+// deepestLink descends from err through the wrapped errors for as long as
+// some child satisfies pred and returns the last link reached
+func deepestLink(err error, pred func(error) bool) error {
+	cur := err
+	for {
+		next := error(nil)
+		for _, child := range unwrapAll(cur) {
+			if child != nil && pred(child) {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return cur
+		}
+		cur = next
+	}
+}
+
+// findLink walks the error tree depth-first, like errors.As, and returns
+// the first link satisfying pred
+func findLink(err error, pred func(error) bool) error {
+	if err == nil {
+		return nil
+	}
+	if pred(err) {
+		return err
+	}
+	for _, child := range unwrapAll(err) {
+		if match := findLink(child, pred); match != nil {
+			return match
+		}
+	}
+	return nil
+}
+
+// unwrapAll returns the errors directly wrapped by err
+func unwrapAll(err error) []error {
+	switch x := err.(type) {
+	case interface{ Unwrap() error }:
+		if inner := x.Unwrap(); inner != nil {
+			return []error{inner}
+		}
+	case interface{ Unwrap() []error }:
+		return x.Unwrap()
+	}
+	return nil
+}
+
+// traceResolution writes the resolution to stderr when ExplainEnv is set
+func traceResolution(w io.Writer, res Resolution) {
+	if os.Getenv(ExplainEnv) == "" {
+		return
+	}
+	fmt.Fprintf(w, "%s: %v\n", ExplainEnv, res)
+}
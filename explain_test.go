@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExplainExitCode(t *testing.T) {
+	_, notExist := os.Open("/nonexistent/cli-test")
+	exitErr := ValidationError("bad input")
+	netErr := dummyNetError{timeout: true}
+
+	tests := []struct {
+		name  string
+		err   error
+		code  ExitCode
+		rule  string
+		match error
+	}{
+		{"nil", nil, ExitCodeSuccess, "nil error", nil},
+		{"exit_error", fmt.Errorf("wrap: %w", exitErr), ExitCodeValidation, "ExitError", exitErr},
+		{"os_not_exist", notExist, ExitCodeNoInput, "os.IsNotExist", notExist},
+		{"net_timeout", fmt.Errorf("dial: %w", netErr), ExitCodeTempFail, "net.Error timeout", netErr},
+		{"sentinel", fmt.Errorf("load: %w", ErrConfig), ExitCodeConfig, `sentinel "configuration error"`, ErrConfig},
+		{"context", fmt.Errorf("wait: %w", context.Canceled), ExitCodeInterrupted, "context.Canceled", context.Canceled},
+		{"default", errors.New("boom"), ExitCodeErrorInternal, "default", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := ExplainExitCode(tt.err)
+			if res.Code != tt.code {
+				t.Errorf("Code = %d, want %d", res.Code, tt.code)
+			}
+			if res.Rule != tt.rule {
+				t.Errorf("Rule = %q, want %q", res.Rule, tt.rule)
+			}
+			if res.Match != tt.match {
+				t.Errorf("Match = %v, want %v", res.Match, tt.match)
+			}
+		})
+	}
+}
+
+func TestExplainExitCode_CustomResolver(t *testing.T) {
+	restoreResolvers(t)
+	inner := &dbError{constraint: "pk"}
+	AppendResolvers(NamedResolver("database", ResolverFunc(resolveDBError)))
+
+	res := ExplainExitCode(fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", inner)))
+	if res.Code != ExitCodeConflict || res.Rule != "database" {
+		t.Errorf("ExplainExitCode() = %+v", res)
+	}
+	if res.Match != inner {
+		t.Errorf("Match = %v, want deepest matching link %v", res.Match, inner)
+	}
+}
+
+func TestRunner_ExplainTrace(t *testing.T) {
+	t.Setenv(ExplainEnv, "1")
+	var stderr bytes.Buffer
+	r := &Runner{Stdout: &bytes.Buffer{}, Stderr: &stderr, Exit: func(int) {}}
+	r.Run(func(context.Context) error { return errors.New("boom") })
+
+	want := ExplainEnv + ": exit code 1 (Internal error) by rule default\n"
+	if !strings.HasSuffix(stderr.String(), want) {
+		t.Errorf("stderr = %q, want suffix %q", stderr.String(), want)
+	}
+}
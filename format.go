@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Format implements fmt.Formatter.
+//
+//	%v, %s  the message, as returned by Error
+//	%q      the quoted message
+//	%+v     the message, then the code, name and category, each wrapped cause
+//	        on its own line (branches of joined errors are numbered and their
+//	        causes indented), the fields and the stack, if captured
+//	%#v     the Go-syntax representation, as returned by GoString
+func (e *ExitError) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		e.writeVerbose(s)
+	case verb == 'v' && s.Flag('#'):
+		io.WriteString(s, e.GoString())
+	default:
+		fmt.Fprintf(s, fmt.FormatString(s, verb), e.Error())
+	}
+}
+
+// GoString implements fmt.GoStringer with the exported fields:
+// &cli.ExitError{Code:78, Message:"missing endpoint", Cause:error(nil)}
+func (e *ExitError) GoString() string {
+	if e == nil {
+		return "(*cli.ExitError)(nil)"
+	}
+	cause := "error(nil)"
+	if e.Cause != nil {
+		cause = fmt.Sprintf("%#v", e.Cause)
+	}
+	return fmt.Sprintf("&cli.ExitError{Code:%d, Message:%q, Cause:%s}", int(e.Code), e.Message, cause)
+}
+
+func (e *ExitError) writeVerbose(w io.Writer) {
+	fmt.Fprintf(w, "%s\ncode: %d (%s), category: %s\n", e.Error(), int(e.Code), e.Code, e.Code.Category())
+	writeCauses(w, e, "")
+	if len(e.fields) > 0 {
+		keys := make([]string, 0, len(e.fields))
+		for k := range e.fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		io.WriteString(w, "fields:")
+		for _, k := range keys {
+			if v, ok := e.fields[k].(string); ok {
+				fmt.Fprintf(w, " %s=%q", k, v)
+			} else {
+				fmt.Fprintf(w, " %s=%v", k, e.fields[k])
+			}
+		}
+		io.WriteString(w, "\n")
+	}
+	if len(e.stack) > 0 {
+		io.WriteString(w, "stack:\n")
+		e.stack.writeTo(w)
+	}
+}
+
+// writeCauses writes one "caused by" line per error wrapped by err. An error
+// wrapping several errors is represented by its numbered branches.
+func writeCauses(w io.Writer, err error, indent string) {
+	children := unwrapAll(err)
+	for i, c := range children {
+		if c == nil {
+			continue
+		}
+		label, next := "caused by", indent
+		if len(children) > 1 {
+			label, next = fmt.Sprintf("caused by [%d]", i), indent+"    "
+		}
+		if len(unwrapAll(c)) > 1 {
+			// Skip the joined text; the branches follow
+			fmt.Fprintf(w, "%s%s: %d errors\n", indent, label, len(unwrapAll(c)))
+		} else {
+			fmt.Fprintf(w, "%s%s: %s\n", indent, label, oneLine(c.Error()))
+		}
+		writeCauses(w, c, next)
+	}
+}
+
+// oneLine joins the lines of a multi-line message
+func oneLine(s string) string {
+	return strings.ReplaceAll(s, "\n", "; ")
+}
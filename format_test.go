@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExitError_FormatVerbs(t *testing.T) {
+	e := WithCode(fmt.Errorf("read config: %w", errors.New(`no "endpoint" key`)), ExitCodeConfig)
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%v", `read config: no "endpoint" key`},
+		{"%s", `read config: no "endpoint" key`},
+		{"%q", `"read config: no \"endpoint\" key"`},
+		{"%.4s", "read"},
+		{"Error: %v", `Error: read config: no "endpoint" key`},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, e); got != tt.want {
+			t.Errorf("Sprintf(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+	if got := fmt.Sprintf("%#v", e); !strings.HasPrefix(got, `&cli.ExitError{Code:78, Message:"read config: no \"endpoint\" key", Cause:&fmt.wrapError{`) {
+		t.Errorf("%%#v = %q", got)
+	}
+	if got, want := fmt.Sprintf("%#v", NewExitError(ExitCodeConfig, "missing endpoint", nil)), `&cli.ExitError{Code:78, Message:"missing endpoint", Cause:error(nil)}`; got != want {
+		t.Errorf("%%#v = %q, want %q", got, want)
+	}
+	if got, want := fmt.Sprintf("%#v", (*ExitError)(nil)), "(*cli.ExitError)(nil)"; got != want {
+		t.Errorf("%%#v of nil = %q, want %q", got, want)
+	}
+}
+
+func TestExitError_FormatVerbose(t *testing.T) {
+	withCaptureStacks(t, false)
+	inner := fmt.Errorf("upload part 3: %w", errors.New("connection reset"))
+	e := NewExitError(ExitCodeIOError, "sync failed", fmt.Errorf("sync: %w", inner)).
+		WithField("bucket", "logs").
+		WithField("attempt", 3)
+
+	want := `sync failed
+code: 74 (I/O error), category: user_error
+caused by: sync: upload part 3: connection reset
+caused by: upload part 3: connection reset
+caused by: connection reset
+fields: attempt=3 bucket="logs"
+`
+	if got := fmt.Sprintf("%+v", e); got != want {
+		t.Errorf("%%+v =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExitError_FormatJoined(t *testing.T) {
+	withCaptureStacks(t, false)
+	joined := errors.Join(
+		fmt.Errorf("row 3: %w", errors.New("bad date")),
+		errors.New("row 7: empty name"),
+	)
+	e := WithCode(joined, ExitCodeValidation)
+
+	got := fmt.Sprintf("%+v", e)
+	want := "caused by: 2 errors\n" +
+		"caused by [0]: row 3: bad date\n" +
+		"    caused by: bad date\n" +
+		"caused by [1]: row 7: empty name\n"
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("%%+v =\n%s\nwant suffix\n%s", got, want)
+	}
+}
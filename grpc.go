@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"reflect"
+)
+
+// Canonical gRPC status codes (google.golang.org/grpc/codes), defined locally
+// so the package stays dependency-free
+const (
+	GRPCCodeOK                 uint32 = 0
+	GRPCCodeCanceled           uint32 = 1
+	GRPCCodeUnknown            uint32 = 2
+	GRPCCodeInvalidArgument    uint32 = 3
+	GRPCCodeDeadlineExceeded   uint32 = 4
+	GRPCCodeNotFound           uint32 = 5
+	GRPCCodeAlreadyExists      uint32 = 6
+	GRPCCodePermissionDenied   uint32 = 7
+	GRPCCodeResourceExhausted  uint32 = 8
+	GRPCCodeFailedPrecondition uint32 = 9
+	GRPCCodeAborted            uint32 = 10
+	GRPCCodeOutOfRange         uint32 = 11
+	GRPCCodeUnimplemented      uint32 = 12
+	GRPCCodeInternal           uint32 = 13
+	GRPCCodeUnavailable        uint32 = 14
+	GRPCCodeDataLoss           uint32 = 15
+	GRPCCodeUnauthenticated    uint32 = 16
+)
+
+// FromGRPCCode maps a gRPC status code to ExitCode
+func FromGRPCCode(code uint32) ExitCode {
+	switch code {
+	case GRPCCodeOK:
+		return ExitCodeSuccess
+	case GRPCCodeCanceled:
+		return ExitCodeInterrupted
+	case GRPCCodeInvalidArgument, GRPCCodeOutOfRange:
+		return ExitCodeValidation
+	case GRPCCodeDeadlineExceeded, GRPCCodeAborted:
+		return ExitCodeTempFail
+	case GRPCCodeNotFound:
+		return ExitCodeNotFound
+	case GRPCCodeAlreadyExists, GRPCCodeFailedPrecondition:
+		return ExitCodeConflict
+	case GRPCCodePermissionDenied:
+		return ExitCodeForbidden
+	case GRPCCodeResourceExhausted:
+		return ExitCodeRateLimit
+	case GRPCCodeUnimplemented:
+		return ExitCodeProtocol
+	case GRPCCodeInternal:
+		return ExitCodeSoftware
+	case GRPCCodeUnavailable:
+		return ExitCodeUnavailable
+	case GRPCCodeDataLoss:
+		return ExitCodeDataError
+	case GRPCCodeUnauthenticated:
+		return ExitCodeAuthFailed
+	default:
+		return ExitCodeErrorInternal
+	}
+}
+
+// ToGRPCCode maps ExitCode to recommended gRPC status code
+func ToGRPCCode(code ExitCode) uint32 {
+	if code == ExitCodeSuccess {
+		return GRPCCodeOK
+	}
+	if info, ok := LookupCode(code); ok && info.GRPCCode != GRPCCodeOK {
+		return info.GRPCCode
+	}
+	if code.Category() == CategorySystemSignal {
+		return GRPCCodeCanceled
+	}
+	return GRPCCodeUnknown
+}
+
+// resolveGRPC maps errors implementing GRPCStatus(), such as those from
+// google.golang.org/grpc/status. The status type is not imported, so the
+// method and the status Code() are discovered by reflection.
+func resolveGRPC(err error) (Resolution, bool) {
+	var code uint32
+	match := findLink(err, func(e error) bool {
+		c, ok := grpcStatusCode(e)
+		code = c
+		return ok
+	})
+	if match == nil {
+		return Resolution{}, false
+	}
+	return Resolution{Code: FromGRPCCode(code), Rule: "GRPCStatus", Match: match}, true
+}
+
+// grpcStatusCode calls err.GRPCStatus().Code() and reports whether it
+// returned a non-OK code
+func grpcStatusCode(err error) (uint32, bool) {
+	m := reflect.ValueOf(err).MethodByName("GRPCStatus")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return 0, false
+	}
+	st := m.Call(nil)[0]
+	if (st.Kind() == reflect.Pointer || st.Kind() == reflect.Interface) && st.IsNil() {
+		return 0, false
+	}
+	c := st.MethodByName("Code")
+	if !c.IsValid() || c.Type().NumIn() != 0 || c.Type().NumOut() != 1 {
+		return 0, false
+	}
+	v := c.Call(nil)[0]
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		code := uint32(v.Uint())
+		return code, code != GRPCCodeOK
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		code := uint32(v.Int())
+		return code, v.Int() > 0
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"testing"
+)
+
+// grpcStatus mimics *status.Status from google.golang.org/grpc
+type grpcStatus struct{ code grpcCode }
+
+type grpcCode uint32
+
+func (s *grpcStatus) Code() grpcCode { return s.code }
+
+// grpcError mimics the error returned by status.Error
+type grpcError struct{ s *grpcStatus }
+
+func (e *grpcError) Error() string           { return fmt.Sprintf("rpc error: code = %d", e.s.code) }
+func (e *grpcError) GRPCStatus() *grpcStatus { return e.s }
+func newGRPCError(code uint32) error         { return &grpcError{&grpcStatus{grpcCode(code)}} }
+
+func TestFromGRPCCode(t *testing.T) {
+	tests := []struct {
+		code uint32
+		want ExitCode
+	}{
+		{GRPCCodeOK, ExitCodeSuccess},
+		{GRPCCodeCanceled, ExitCodeInterrupted},
+		{GRPCCodeInvalidArgument, ExitCodeValidation},
+		{GRPCCodeDeadlineExceeded, ExitCodeTempFail},
+		{GRPCCodeNotFound, ExitCodeNotFound},
+		{GRPCCodeAlreadyExists, ExitCodeConflict},
+		{GRPCCodePermissionDenied, ExitCodeForbidden},
+		{GRPCCodeResourceExhausted, ExitCodeRateLimit},
+		{GRPCCodeFailedPrecondition, ExitCodeConflict},
+		{GRPCCodeUnimplemented, ExitCodeProtocol},
+		{GRPCCodeInternal, ExitCodeSoftware},
+		{GRPCCodeUnavailable, ExitCodeUnavailable},
+		{GRPCCodeDataLoss, ExitCodeDataError},
+		{GRPCCodeUnauthenticated, ExitCodeAuthFailed},
+		{99, ExitCodeErrorInternal},
+	}
+	for _, tt := range tests {
+		if got := FromGRPCCode(tt.code); got != tt.want {
+			t.Errorf("FromGRPCCode(%d) = %d, want %d", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestToGRPCCode(t *testing.T) {
+	tests := []struct {
+		code ExitCode
+		want uint32
+	}{
+		{ExitCodeSuccess, GRPCCodeOK},
+		{ExitCodeNotFound, GRPCCodeNotFound},
+		{ExitCodeForbidden, GRPCCodePermissionDenied},
+		{ExitCodeAuthRequired, GRPCCodeUnauthenticated},
+		{ExitCodeRateLimit, GRPCCodeResourceExhausted},
+		{ExitCodeUnavailable, GRPCCodeUnavailable},
+		{ExitCodeConfig, GRPCCodeFailedPrecondition},
+		{ExitCodeValidation, GRPCCodeInvalidArgument},
+		{ExitCodeInterrupted, GRPCCodeCanceled},
+		{ExitCodeErrorInternal, GRPCCodeUnknown},
+		{ExitCode(120), GRPCCodeUnknown},
+	}
+	for _, tt := range tests {
+		if got := ToGRPCCode(tt.code); got != tt.want {
+			t.Errorf("ToGRPCCode(%d) = %d, want %d", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestResolveExitCode_GRPCStatus(t *testing.T) {
+	err := fmt.Errorf("listing volumes: %w", newGRPCError(GRPCCodeUnauthenticated))
+	res := ExplainExitCode(err)
+	if res.Code != ExitCodeAuthFailed || res.Rule != "GRPCStatus" {
+		t.Errorf("ExplainExitCode() = %v, want AuthFailed by GRPCStatus", res)
+	}
+
+	// OK status and nil status are not matches
+	if got := ResolveExitCode(newGRPCError(GRPCCodeOK)); got != ExitCodeErrorInternal {
+		t.Errorf("OK status resolved to %d", got)
+	}
+	if got := ResolveExitCode(&grpcError{}); got != ExitCodeErrorInternal {
+		t.Errorf("nil status resolved to %d", got)
+	}
+
+	// An explicit ExitError still wins
+	err = NewExitError(ExitCodeConfig, "bad endpoint", newGRPCError(GRPCCodeUnavailable))
+	if got := ResolveExitCode(err); got != ExitCodeConfig {
+		t.Errorf("ResolveExitCode(ExitError) = %d, want %d", got, ExitCodeConfig)
+	}
+}
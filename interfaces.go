@@ -0,0 +1,54 @@
+package cli
+
+// Well-known method sets of third-party errors, recognized anywhere in the
+// error chain. When several are present the first in this order wins:
+//
+//	ExitCode() int    - a process exit status in 1..255 (os/exec style)
+//	HTTPStatus() int  - an HTTP status >= 400, mapped with FromHTTPStatus
+//	StatusCode() int  - an HTTP status >= 400, mapped with FromHTTPStatus
+//	Timeout() bool    - true maps to ExitCodeTempFail
+//
+// The status methods are consulted right after the gRPC and database rules;
+// Timeout() only after the net.Error rules, so that context, errno and net
+// errors keep their more specific rules.
+type (
+	exitCoder    interface{ ExitCode() int }
+	httpStatuser interface{ HTTPStatus() int }
+	statusCoder  interface{ StatusCode() int }
+	timeouter    interface{ Timeout() bool }
+)
+
+// resolveStatusMethods maps errors implementing ExitCode(), HTTPStatus() or StatusCode()
+func resolveStatusMethods(err error) (Resolution, bool) {
+	if match := findLink(err, func(e error) bool {
+		c, ok := e.(exitCoder)
+		return ok && c.ExitCode() > 0 && c.ExitCode() <= 255
+	}); match != nil {
+		return Resolution{Code: ExitCode(match.(exitCoder).ExitCode()), Rule: "ExitCode()", Match: match}, true
+	}
+	if match := findLink(err, func(e error) bool {
+		s, ok := e.(httpStatuser)
+		return ok && s.HTTPStatus() >= 400
+	}); match != nil {
+		return Resolution{Code: FromHTTPStatus(match.(httpStatuser).HTTPStatus()), Rule: "HTTPStatus()", Match: match}, true
+	}
+	if match := findLink(err, func(e error) bool {
+		s, ok := e.(statusCoder)
+		return ok && s.StatusCode() >= 400
+	}); match != nil {
+		return Resolution{Code: FromHTTPStatus(match.(statusCoder).StatusCode()), Rule: "StatusCode()", Match: match}, true
+	}
+	return Resolution{}, false
+}
+
+// resolveTimeoutMethod maps errors whose Timeout() reports true
+func resolveTimeoutMethod(err error) (Resolution, bool) {
+	match := findLink(err, func(e error) bool {
+		t, ok := e.(timeouter)
+		return ok && t.Timeout()
+	})
+	if match == nil {
+		return Resolution{}, false
+	}
+	return Resolution{Code: ExitCodeTempFail, Rule: "Timeout()", Match: match}, true
+}
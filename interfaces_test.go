@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+type exitCodeErr int
+
+func (e exitCodeErr) Error() string { return fmt.Sprintf("exited with %d", int(e)) }
+func (e exitCodeErr) ExitCode() int { return int(e) }
+
+type apiErr struct{ status int }
+
+func (e *apiErr) Error() string   { return fmt.Sprintf("api: status %d", e.status) }
+func (e *apiErr) StatusCode() int { return e.status }
+
+type httpStatusErr struct{ status int }
+
+func (e httpStatusErr) Error() string   { return fmt.Sprintf("status %d", e.status) }
+func (e httpStatusErr) HTTPStatus() int { return e.status }
+
+type timeoutErr struct{ timeout bool }
+
+func (e timeoutErr) Error() string { return "operation timed out" }
+func (e timeoutErr) Timeout() bool { return e.timeout }
+
+func TestResolveExitCode_Interfaces(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ExitCode
+		rule string
+	}{
+		{"exit_code", fmt.Errorf("terraform apply: %w", exitCodeErr(3)), 3, "ExitCode()"},
+		{"exit_code_out_of_range", exitCodeErr(-1), ExitCodeErrorInternal, "default"},
+		{"http_status", httpStatusErr{404}, ExitCodeNotFound, "HTTPStatus()"},
+		{"status_code", fmt.Errorf("listing: %w", &apiErr{429}), ExitCodeRateLimit, "StatusCode()"},
+		{"status_code_success", &apiErr{200}, ExitCodeErrorInternal, "default"},
+		{"timeout", fmt.Errorf("upload: %w", timeoutErr{true}), ExitCodeTempFail, "Timeout()"},
+		{"no_timeout", timeoutErr{false}, ExitCodeErrorInternal, "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := ExplainExitCode(tt.err)
+			if res.Code != tt.want || res.Rule != tt.rule {
+				t.Errorf("ExplainExitCode(%v) = %d by %q, want %d by %q", tt.err, res.Code, res.Rule, tt.want, tt.rule)
+			}
+		})
+	}
+}
+
+// layeredErr implements every method set
+type layeredErr struct{}
+
+func (layeredErr) Error() string   { return "layered" }
+func (layeredErr) ExitCode() int   { return int(ExitCodeConfig) }
+func (layeredErr) HTTPStatus() int { return 403 }
+func (layeredErr) StatusCode() int { return 404 }
+func (layeredErr) Timeout() bool   { return true }
+
+func TestResolveExitCode_InterfacePrecedence(t *testing.T) {
+	if got := ResolveExitCode(layeredErr{}); got != ExitCodeConfig {
+		t.Errorf("ExitCode() should win, got %d", got)
+	}
+
+	// A deeper ExitCode() still wins over an outer StatusCode()
+	err := &wrappingAPIErr{apiErr{503}, exitCodeErr(int(ExitCodeNoUser))}
+	if got := ResolveExitCode(err); got != ExitCodeNoUser {
+		t.Errorf("ResolveExitCode() = %d, want %d", got, ExitCodeNoUser)
+	}
+
+	// Explicit ExitErrors win over every method set
+	if got := ResolveExitCode(WithCode(layeredErr{}, ExitCodeIOError)); got != ExitCodeIOError {
+		t.Errorf("ResolveExitCode(ExitError) = %d, want %d", got, ExitCodeIOError)
+	}
+
+	// context keeps its own rule although DeadlineExceeded has Timeout()
+	if res := ExplainExitCode(fmt.Errorf("fetch: %w", context.DeadlineExceeded)); res.Rule != "context.DeadlineExceeded" {
+		t.Errorf("rule = %q, want context.DeadlineExceeded", res.Rule)
+	}
+}
+
+type wrappingAPIErr struct {
+	apiErr
+	cause error
+}
+
+func (e *wrappingAPIErr) Unwrap() error { return e.cause }
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// TLS alerts a server sends when it rejects the client certificate (RFC 8446 section 6)
+var clientCertAlerts = map[uint8]bool{
+	42:  true, // bad_certificate
+	43:  true, // unsupported_certificate
+	44:  true, // certificate_revoked
+	45:  true, // certificate_expired
+	46:  true, // certificate_unknown
+	48:  true, // unknown_ca
+	116: true, // certificate_required
+}
+
+// resolveNetDetail tells apart the network failures that the generic net.Error
+// rule would report as TempFail or Unavailable: unknown hosts, TLS and
+// certificate failures and malformed HTTP responses
+func resolveNetDetail(err error) (Resolution, bool) {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return Resolution{Code: ExitCodeNoHost, Rule: "net.DNSError not found", Match: dnsErr}, true
+	}
+	if match, alert := tlsAlert(err); match != nil {
+		if clientCertAlerts[alert] {
+			return Resolution{Code: ExitCodeAuthFailed, Rule: "tls client certificate rejected", Match: match}, true
+		}
+		return Resolution{Code: ExitCodeProtocol, Rule: "tls alert", Match: match}, true
+	}
+	if match := certificateError(err); match != nil {
+		return Resolution{Code: ExitCodeProtocol, Rule: "x509 verification", Match: match}, true
+	}
+	var recordErr tls.RecordHeaderError
+	if errors.As(err, &recordErr) {
+		return Resolution{Code: ExitCodeProtocol, Rule: "tls record header", Match: recordErr}, true
+	}
+	if errors.Is(err, http.ErrSchemeMismatch) {
+		return matchSentinel(err, http.ErrSchemeMismatch, ExitCodeProtocol, "http.ErrSchemeMismatch"), true
+	}
+	var protoErr textproto.ProtocolError
+	if errors.As(err, &protoErr) {
+		return Resolution{Code: ExitCodeProtocol, Rule: "textproto.ProtocolError", Match: protoErr}, true
+	}
+	var httpProtoErr *http.ProtocolError
+	if errors.As(err, &httpProtoErr) {
+		return Resolution{Code: ExitCodeProtocol, Rule: "http.ProtocolError", Match: httpProtoErr}, true
+	}
+	if match := malformedHTTP(err); match != nil {
+		return Resolution{Code: ExitCodeProtocol, Rule: "malformed HTTP response", Match: match}, true
+	}
+	return Resolution{}, false
+}
+
+// malformedHTTP finds the untyped error net/http reports for an unparsable
+// response by its "malformed HTTP " message prefix, the one text check of the
+// net rules. Only links under a *url.Error are inspected; their Error methods
+// may panic and are called through safeErrorText.
+func malformedHTTP(err error) error {
+	var ue *url.Error
+	if !errors.As(err, &ue) {
+		return nil
+	}
+	for cur := ue.Err; cur != nil; cur = errors.Unwrap(cur) {
+		if strings.HasPrefix(safeErrorText(cur), "malformed HTTP ") {
+			return cur
+		}
+	}
+	return nil
+}
+
+// safeErrorText returns err.Error(), or "" if it panics
+func safeErrorText(err error) (s string) {
+	defer func() {
+		if recover() != nil {
+			s = ""
+		}
+	}()
+	return err.Error()
+}
+
+// tlsAlert finds a TLS alert received from the peer. crypto/tls reports it as
+// a *net.OpError with Op "remote error" wrapping an unexported uint8 type, or
+// (for QUIC) as a tls.AlertError.
+func tlsAlert(err error) (error, uint8) {
+	var alertErr tls.AlertError
+	if errors.As(err, &alertErr) {
+		return alertErr, uint8(alertErr)
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || opErr.Op != "remote error" || opErr.Err == nil {
+		return nil, 0
+	}
+	if v := reflect.ValueOf(opErr.Err); v.Kind() == reflect.Uint8 {
+		return opErr, uint8(v.Uint())
+	}
+	return nil, 0
+}
+
+// certificateError finds a certificate verification failure
+func certificateError(err error) error {
+	var verifyErr *tls.CertificateVerificationError
+	if errors.As(err, &verifyErr) {
+		return verifyErr
+	}
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		hostname         x509.HostnameError
+		invalid          x509.CertificateInvalidError
+		systemRoots      x509.SystemRootsError
+	)
+	switch {
+	case errors.As(err, &unknownAuthority):
+		return unknownAuthority
+	case errors.As(err, &hostname):
+		return hostname
+	case errors.As(err, &invalid):
+		return invalid
+	case errors.As(err, &systemRoots):
+		return systemRoots
+	}
+	return nil
+}
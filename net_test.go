@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"testing"
+)
+
+// fakeAlert mimics the unexported alert type of crypto/tls
+type fakeAlert uint8
+
+func (a fakeAlert) Error() string { return fmt.Sprintf("tls: alert(%d)", uint8(a)) }
+
+func TestResolveExitCode_NetDetail(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ExitCode
+		rule string
+	}{
+		{"dns_not_found", &net.DNSError{Err: "no such host", Name: "nope.invalid", IsNotFound: true}, ExitCodeNoHost, "net.DNSError not found"},
+		{"dns_timeout", &net.DNSError{Err: "i/o timeout", Name: "example.com", IsTimeout: true}, ExitCodeTempFail, "net.Error timeout"},
+		{"url_dns", &url.Error{Op: "Get", URL: "https://nope.invalid", Err: &net.DNSError{Name: "nope.invalid", IsNotFound: true}}, ExitCodeNoHost, "net.DNSError not found"},
+		{"client_cert_rejected", &net.OpError{Op: "remote error", Err: fakeAlert(42)}, ExitCodeAuthFailed, "tls client certificate rejected"},
+		{"cert_required", tls.AlertError(116), ExitCodeAuthFailed, "tls client certificate rejected"},
+		{"handshake_failure", &net.OpError{Op: "remote error", Err: fakeAlert(40)}, ExitCodeProtocol, "tls alert"},
+		{"unknown_authority", &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}, ExitCodeProtocol, "x509 verification"},
+		{"hostname", fmt.Errorf("verify: %w", x509.HostnameError{Host: "example.com"}), ExitCodeProtocol, "x509 verification"},
+		{"record_header", tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}, ExitCodeProtocol, "tls record header"},
+		{"textproto", &url.Error{Op: "Get", URL: "http://x", Err: textproto.ProtocolError("malformed MIME header line")}, ExitCodeProtocol, "textproto.ProtocolError"},
+		{"scheme_mismatch", &url.Error{Op: "Get", URL: "https://x", Err: http.ErrSchemeMismatch}, ExitCodeProtocol, "http.ErrSchemeMismatch"},
+		{"url_timeout", &url.Error{Op: "Get", URL: "http://x", Err: &net.OpError{Op: "dial", Err: dummyNetError{timeout: true}}}, ExitCodeTempFail, "net.Error timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := ExplainExitCode(tt.err)
+			if res.Code != tt.want || res.Rule != tt.rule {
+				t.Errorf("ExplainExitCode(%v) = %d by %q, want %d by %q", tt.err, res.Code, res.Rule, tt.want, tt.rule)
+			}
+		})
+	}
+}
+
+func TestResolveExitCode_HTTPClientFailures(t *testing.T) {
+	// Untrusted server certificate
+	quiet := log.New(io.Discard, "", 0)
+	tlsSrv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	tlsSrv.Config.ErrorLog = quiet
+	tlsSrv.StartTLS()
+	defer tlsSrv.Close()
+	_, err := http.Get(tlsSrv.URL)
+	if got := ResolveExitCode(err); got != ExitCodeProtocol {
+		t.Errorf("untrusted certificate: ResolveExitCode(%v) = %d, want %d", err, got, ExitCodeProtocol)
+	}
+
+	// Client certificate required but not sent
+	mtlsSrv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	mtlsSrv.Config.ErrorLog = quiet
+	mtlsSrv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	mtlsSrv.StartTLS()
+	defer mtlsSrv.Close()
+	_, err = mtlsSrv.Client().Get(mtlsSrv.URL)
+	if got := ResolveExitCode(err); got != ExitCodeAuthFailed {
+		t.Errorf("missing client certificate: ResolveExitCode(%v) = %d, want %d", err, got, ExitCodeAuthFailed)
+	}
+
+	// Garbage instead of an HTTP status line
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 4096)
+		_, _ = conn.Read(buf)
+		_, _ = conn.Write([]byte("SSH-2.0-OpenSSH_9.6\r\n\r\n"))
+	}()
+	_, err = http.Get("http://" + ln.Addr().String())
+	if got := ResolveExitCode(err); got != ExitCodeProtocol {
+		t.Errorf("malformed response: ResolveExitCode(%v) = %d, want %d", err, got, ExitCodeProtocol)
+	}
+}
+
+// panickyErr has an Error method that panics, like a nil-status gRPC error
+type panickyErr struct{ msg *string }
+
+func (e panickyErr) Error() string { return *e.msg }
+
+func TestResolveExitCode_MalformedHTTPScope(t *testing.T) {
+	malformed := errors.New(`malformed HTTP response "SSH-2.0"`)
+	if got := ResolveExitCode(&url.Error{Op: "Get", URL: "http://x", Err: fmt.Errorf("read: %w", malformed)}); got != ExitCodeProtocol {
+		t.Errorf("under url.Error: ResolveExitCode() = %d, want %d", got, ExitCodeProtocol)
+	}
+	if got := ResolveExitCode(malformed); got != ExitCodeErrorInternal {
+		t.Errorf("outside url.Error: ResolveExitCode() = %d, want %d", got, ExitCodeErrorInternal)
+	}
+	// A panicking Error method is not a match
+	err := &url.Error{Op: "Get", URL: "http://x", Err: panickyErr{}}
+	if got := ResolveExitCode(err); got != ExitCodeErrorInternal {
+		t.Errorf("ResolveExitCode(panicky) = %d", got)
+	}
+}
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// OutputEnv names the environment variable selecting the output mode of
+// Renderers left at OutputDefault, and of Run when Runner.Renderer is nil
+const OutputEnv = "CLI_OUTPUT"
+
+// OutputMode selects how a Renderer writes errors
+type OutputMode int
+
+const (
+	// OutputDefault uses the mode named by $CLI_OUTPUT, or OutputText
+	OutputDefault OutputMode = iota
+	// OutputText writes the human layout
+	OutputText
+	// OutputJSON writes one JSON Lines event
+	OutputJSON
+)
+
+// ParseOutputMode parses "text" or "json", e.g. the value of an --output flag.
+// The empty string is OutputDefault.
+func ParseOutputMode(s string) (OutputMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return OutputDefault, nil
+	case "text":
+		return OutputText, nil
+	case "json":
+		return OutputJSON, nil
+	}
+	return OutputDefault, fmt.Errorf("unknown output mode %q (want text or json)", s)
+}
+
+// String returns the mode name accepted by ParseOutputMode
+func (m OutputMode) String() string {
+	switch m {
+	case OutputText:
+		return "text"
+	case OutputJSON:
+		return "json"
+	}
+	return ""
+}
+
+// outputMode resolves OutputDefault through OutputEnv
+func outputMode(m OutputMode) OutputMode {
+	if m != OutputDefault {
+		return m
+	}
+	if env, err := ParseOutputMode(os.Getenv(OutputEnv)); err == nil && env != OutputDefault {
+		return env
+	}
+	return OutputText
+}
+
+// errorEvent is a JSON Lines error event: the ExitError wire format plus
+// context about the invocation. It decodes with ExitError.UnmarshalJSON.
+type errorEvent struct {
+	exitErrorJSON
+	Timestamp      string   `json:"timestamp"`
+	Program        string   `json:"program"`
+	ProgramVersion string   `json:"program_version,omitempty"`
+	Args           []string `json:"args"`
+	// Duration is the run time in seconds, omitted when Renderer.Start is unset
+	Duration *float64 `json:"duration,omitempty"`
+	ExitCode int      `json:"exit_code"`
+}
+
+// renderJSON writes err as a single JSON line
+func (r *Renderer) renderJSON(w io.Writer, err error) error {
+	res := ExplainExitCode(err)
+	exitErr, ok := err.(*ExitError)
+	if !ok {
+		// Keep the context of a wrapped ExitError under the outer message
+		exitErr = &ExitError{Code: res.Code, Message: err.Error(), Cause: errors.Unwrap(err), hints: errorHints(err)}
+		var inner *ExitError
+		if errors.As(err, &inner) {
+			exitErr.fields, exitErr.stack = inner.fields, inner.stack
+		}
+	}
+
+	now := r.clock()
+	args := r.Args
+	if args == nil {
+		args = os.Args[1:]
+	}
+	ev := errorEvent{
+		exitErrorJSON:  exitErr.wire(),
+		Timestamp:      now.UTC().Format(time.RFC3339Nano),
+		Program:        r.program(),
+		ProgramVersion: r.Version,
+		Args:           RedactArgs(args),
+		ExitCode:       int(res.Code),
+	}
+	if !r.Start.IsZero() {
+		d := now.Sub(r.Start).Seconds()
+		ev.Duration = &d
+	}
+
+	data, mErr := json.Marshal(ev)
+	if mErr != nil {
+		return mErr
+	}
+	_, wErr := w.Write(append(data, '\n'))
+	return wErr
+}
+
+func (r *Renderer) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+// Redacted replaces secret values in RedactArgs output
+const Redacted = "REDACTED"
+
+// secretSegment matches a segment of a flag or variable name whose value is
+// secret ("key" in "aws-secret-access-key", "PASSWORD" in "DB_PASSWORD")
+var secretSegment = regexp.MustCompile(`(?i)^(key|pass|.*(password|passwd|passphrase|secret|token|apikey)|auth|auth[^o].*|authorization|credential.*)$`)
+
+// switchName matches boolean flag names ("no-auth", "use-token") that take
+// no value even though they look secret
+var switchName = regexp.MustCompile(`(?i)^(no|use|with|without|enable|disable|skip)[-_]`)
+
+// secretName reports whether any "-", "_" or "." separated segment of name
+// looks secret
+func secretName(name string) bool {
+	for _, seg := range strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+		if secretSegment.MatchString(seg) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactArgs returns a copy of args with secret values replaced by Redacted:
+// values of flags whose name looks secret ("--token=x", "--password x",
+// "-api-key x"), NAME=value pairs with such names and passwords in URLs,
+// also as flag values. The argument after a secret flag without "=" is always
+// redacted, unless the flag is a switch ("--no-auth").
+func RedactArgs(args []string) []string {
+	out := make([]string, len(args))
+	redactNext := false
+	for i, arg := range args {
+		switch {
+		case redactNext:
+			out[i] = Redacted
+			redactNext = false
+		case strings.HasPrefix(arg, "-") && arg != "-" && arg != "--":
+			name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+			secret := secretName(name)
+			switch {
+			case secret && hasValue:
+				out[i] = arg[:strings.Index(arg, "=")+1] + Redacted
+			case secret:
+				out[i] = arg
+				redactNext = !switchName.MatchString(name)
+			case hasValue:
+				eq := strings.Index(arg, "=") + 1
+				out[i] = arg[:eq] + redactURL(arg[eq:])
+			default:
+				out[i] = arg
+			}
+		default:
+			out[i] = redactValue(arg)
+		}
+	}
+	return out
+}
+
+// redactValue redacts NAME=value pairs with secret names and URL passwords
+func redactValue(arg string) string {
+	if name, _, ok := strings.Cut(arg, "="); ok && !strings.Contains(name, "/") && secretName(name) {
+		return name + "=" + Redacted
+	}
+	return redactURL(arg)
+}
+
+// redactURL redacts the password of a URL with credentials
+func redactURL(s string) string {
+	if u, err := url.Parse(s); err == nil && u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			u.User = url.UserPassword(u.User.Username(), Redacted)
+			return u.String()
+		}
+	}
+	return s
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CrashDirEnv names the environment variable with the directory Run writes
+// crash reports to when Runner.CrashDir is empty
+const CrashDirEnv = "CLI_CRASH_DIR"
+
+// PanicError is a recovered panic. It unwraps to the panic value when that
+// value is an error.
+type PanicError struct {
+	Value any
+	// Stack is the stack of the panicking goroutine
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Recover converts a panic into an *ExitError with ExitCodeSoftware wrapping
+// a *PanicError, and stores it in *errp. It must be deferred directly:
+//
+//	func work() (err error) {
+//		defer cli.Recover(&err)
+//		...
+//	}
+func Recover(errp *error) {
+	if v := recover(); v != nil {
+		*errp = newPanicError(v)
+	}
+}
+
+func newPanicError(v any) *ExitError {
+	pe := &PanicError{Value: v, Stack: debug.Stack()}
+	return NewExitError(ExitCodeSoftware, pe.Error(), pe)
+}
+
+// WriteCrashReport writes a crash report for pe to a new file in dir and
+// returns its path. The report holds the panic value and stack, a dump of
+// all goroutines, the build info and the command line, with secrets removed
+// by RedactArgs.
+func WriteCrashReport(dir string, pe *PanicError) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	pattern := fmt.Sprintf("%s-crash-%s-%d-*.txt", filepath.Base(os.Args[0]), now.UTC().Format("20060102T150405Z"), os.Getpid())
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "panic: %v\n\n", pe.Value)
+	fmt.Fprintf(&b, "time: %s\n", now.Format(time.RFC3339))
+	fmt.Fprintf(&b, "go: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "args: %s\n", quoteArgs(append(os.Args[:1:1], RedactArgs(os.Args[1:])...)))
+	b.WriteString("\n== panicking goroutine ==\n")
+	b.Write(pe.Stack)
+	b.WriteString("\n== goroutines ==\n")
+	b.Write(allStacks())
+	b.WriteString("\n== build info ==\n")
+	if info, ok := debug.ReadBuildInfo(); ok {
+		b.WriteString(info.String())
+	} else {
+		b.WriteString("unavailable\n")
+	}
+
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(b.Bytes()); err != nil {
+		f.Close()
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+// allStacks returns the stacks of all goroutines, growing the buffer as needed
+func allStacks() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= 16<<20 {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+func quoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = strconv.Quote(a)
+	}
+	return strings.Join(quoted, " ")
+}
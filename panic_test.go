@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func panicky(v any) (err error) {
+	defer Recover(&err)
+	panic(v)
+}
+
+func TestRecover(t *testing.T) {
+	cause := errors.New("nil map")
+	err := panicky(cause)
+
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != ExitCodeSoftware {
+		t.Fatalf("Recover() = %v, want ExitCodeSoftware ExitError", err)
+	}
+	if err.Error() != "panic: nil map" {
+		t.Errorf("message = %q", err.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Error("panic value should be reachable with errors.Is")
+	}
+	var pe *PanicError
+	if !errors.As(err, &pe) || pe.Value != cause {
+		t.Fatalf("PanicError = %+v", pe)
+	}
+	if !bytes.Contains(pe.Stack, []byte("cli.panicky")) {
+		t.Errorf("stack does not include the panicking function:\n%s", pe.Stack)
+	}
+}
+
+func TestRecover_NoPanic(t *testing.T) {
+	want := errors.New("plain")
+	err := func() (err error) {
+		defer Recover(&err)
+		return want
+	}()
+	if err != want {
+		t.Errorf("Recover() changed the error to %v", err)
+	}
+}
+
+func TestWriteCrashReport(t *testing.T) {
+	var pe *PanicError
+	errors.As(panicky("index out of range"), &pe)
+
+	dir := filepath.Join(t.TempDir(), "crashes")
+	path, err := WriteCrashReport(dir, pe)
+	if err != nil {
+		t.Fatalf("WriteCrashReport() error: %v", err)
+	}
+	if filepath.Dir(path) != dir {
+		t.Errorf("report path %s not in %s", path, dir)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := string(data)
+	for _, want := range []string{"panic: index out of range", "args: ", "== panicking goroutine ==", "cli.panicky", "== goroutines ==", "goroutine ", "== build info =="} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+}
+
+func TestWriteCrashReport_RedactsArgs(t *testing.T) {
+	args := os.Args
+	t.Cleanup(func() { os.Args = args })
+	os.Args = []string{args[0], "deploy", "--token=tok-123", "--password", "hunter2"}
+
+	var pe *PanicError
+	errors.As(panicky("boom"), &pe)
+	path, err := WriteCrashReport(t.TempDir(), pe)
+	if err != nil {
+		t.Fatalf("WriteCrashReport() error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := string(data)
+	for _, secret := range []string{"tok-123", "hunter2"} {
+		if strings.Contains(report, secret) {
+			t.Errorf("report contains secret %q", secret)
+		}
+	}
+	if !strings.Contains(report, "--token=REDACTED") {
+		t.Errorf("report args not redacted:\n%s", report)
+	}
+}
+
+func TestRunner_CrashReport(t *testing.T) {
+	dir := t.TempDir()
+	var stderr bytes.Buffer
+	code := -1
+	r := &Runner{Stdout: &bytes.Buffer{}, Stderr: &stderr, Exit: func(c int) { code = c }, CrashDir: dir}
+	r.Run(func(context.Context) error {
+		var m map[string]int
+		m["x"]++
+		return nil
+	})
+
+	if code != int(ExitCodeSoftware) {
+		t.Errorf("exit code = %d, want %d", code, ExitCodeSoftware)
+	}
+	reports, _ := filepath.Glob(filepath.Join(dir, "*-crash-*.txt"))
+	if len(reports) != 1 {
+		t.Fatalf("crash reports = %v, want one", reports)
+	}
+	if !strings.Contains(stderr.String(), "Crash report written to "+reports[0]) {
+		t.Errorf("stderr = %q, want report path", stderr.String())
+	}
+}
+
+func TestRunner_CrashDirEnv(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(CrashDirEnv, dir)
+	runForTest(t, func(context.Context) error { panic("boom") })
+	if reports, _ := filepath.Glob(filepath.Join(dir, "*-crash-*.txt")); len(reports) != 1 {
+		t.Errorf("crash reports = %v, want one", reports)
+	}
+}
+
+func TestRunner_CrashReportJSON(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(OutputEnv, "json")
+	var stderr bytes.Buffer
+	r := &Runner{Stdout: &bytes.Buffer{}, Stderr: &stderr, Exit: func(int) {}, CrashDir: dir}
+	r.Run(func(context.Context) error { panic("boom") })
+
+	reports, _ := filepath.Glob(filepath.Join(dir, "*-crash-*.txt"))
+	if len(reports) != 1 {
+		t.Fatalf("crash reports = %v, want one", reports)
+	}
+	lines := strings.Split(strings.TrimSuffix(stderr.String(), "\n"), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("stderr = %q, want a single JSON line", stderr.String())
+	}
+	var ev struct {
+		Fields map[string]any `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &ev); err != nil {
+		t.Fatalf("stderr is not JSON: %v", err)
+	}
+	if ev.Fields["crash_report"] != reports[0] {
+		t.Errorf("crash_report = %v, want %q", ev.Fields["crash_report"], reports[0])
+	}
+}
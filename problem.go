@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ProblemContentType is the media type of RFC 9457 problem details
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypePrefix prefixes the exit code in the "type" member of problems
+// built from errors. Set it once at startup to point at your documentation,
+// e.g. "https://example.com/errors/".
+var ProblemTypePrefix = "urn:cli:exit-code:"
+
+// Problem is an RFC 9457 problem details object
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Extensions are serialized as additional top-level members
+	Extensions map[string]any `json:"-"`
+}
+
+// MarshalJSON implements json.Marshaler, inlining the extension members.
+// Standard members take precedence over extensions with the same name.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		m[k] = v
+	}
+	for k, v := range map[string]string{"type": p.Type, "title": p.Title, "detail": p.Detail, "instance": p.Instance} {
+		if v != "" {
+			m[k] = v
+		} else {
+			delete(m, k)
+		}
+	}
+	if p.Status != 0 {
+		m["status"] = p.Status
+	} else {
+		delete(m, "status")
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, collecting unknown members in Extensions
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	*p = Problem{}
+	for k, raw := range members {
+		var err error
+		switch k {
+		case "type":
+			err = json.Unmarshal(raw, &p.Type)
+		case "title":
+			err = json.Unmarshal(raw, &p.Title)
+		case "status":
+			err = json.Unmarshal(raw, &p.Status)
+		case "detail":
+			err = json.Unmarshal(raw, &p.Detail)
+		case "instance":
+			err = json.Unmarshal(raw, &p.Instance)
+		default:
+			var v any
+			if err = json.Unmarshal(raw, &v); err == nil {
+				if p.Extensions == nil {
+					p.Extensions = make(map[string]any)
+				}
+				p.Extensions[k] = v
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("problem member %q: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// Error implements error so a parsed problem can travel in an error chain
+func (p *Problem) Error() string {
+	switch {
+	case p.Title != "" && p.Detail != "":
+		return p.Title + ": " + p.Detail
+	case p.Detail != "":
+		return p.Detail
+	case p.Title != "":
+		return p.Title
+	default:
+		return http.StatusText(p.Status)
+	}
+}
+
+// NewProblem builds the problem details for err. The status comes from
+// ToHTTPStatus(ResolveExitCode(err)); type and title come from the exit code
+// and the "code", "category" and "fields" extension members from the
+// ExitError. For 5xx statuses the detail and fields are omitted so internal
+// causes are not exposed to clients. A nil error returns nil; an error that
+// resolves to ExitCodeSuccess is reported as ExitCodeErrorInternal (500).
+func NewProblem(err error) *Problem {
+	if err == nil {
+		return nil
+	}
+	code := ResolveExitCode(err)
+	if code == ExitCodeSuccess {
+		code = ExitCodeErrorInternal
+	}
+	status := ToHTTPStatus(code)
+	p := &Problem{
+		Type:   ProblemTypePrefix + strconv.Itoa(int(code)),
+		Title:  code.String(),
+		Status: status,
+		Extensions: map[string]any{
+			"code":     int(code),
+			"category": code.Category(),
+		},
+	}
+	if status >= 500 {
+		return p
+	}
+
+	p.Detail = err.Error()
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		if fields := exitErr.Fields(); fields != nil {
+			p.Extensions["fields"] = fields
+		}
+	}
+	return p
+}
+
+// WriteProblem writes err to w as an application/problem+json response.
+// A retry-after hint carried by err (see WithRetryAfter) is sent as the
+// Retry-After header. A nil error writes nothing.
+func WriteProblem(w http.ResponseWriter, err error) {
+	if err == nil {
+		return
+	}
+	p := NewProblem(err)
+	body, mErr := json.Marshal(p)
+	if mErr != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", ProblemContentType)
+	h.Set("X-Content-Type-Options", "nosniff")
+	if d, ok := RetryAfter(err); ok && d > 0 {
+		h.Set("Retry-After", strconv.Itoa(int((d+time.Second-1)/time.Second)))
+	}
+	w.WriteHeader(p.Status)
+	_, _ = w.Write(body)
+}
+
+// HandlerFunc is an HTTP handler that returns an error.
+// A non-nil error is written as an application/problem+json response, so
+// handlers can return ExitErrors just like commands do.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP calls f and writes its error with WriteProblem
+func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := f(w, r); err != nil {
+		WriteProblem(w, err)
+	}
+}
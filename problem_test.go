@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func serveProblem(t *testing.T, err error) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+	h := HandlerFunc(func(http.ResponseWriter, *http.Request) error { return err })
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/volumes/vol-1", nil))
+
+	var body map[string]any
+	if uErr := json.Unmarshal(rec.Body.Bytes(), &body); uErr != nil {
+		t.Fatalf("invalid problem body %q: %v", rec.Body.String(), uErr)
+	}
+	return rec, body
+}
+
+func TestHandlerFunc_ClientError(t *testing.T) {
+	rec, body := serveProblem(t, NotFoundError("volume vol-1").WithField("volume_id", "vol-1"))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	want := map[string]any{
+		"type":     "urn:cli:exit-code:83",
+		"title":    "Not found",
+		"status":   float64(404),
+		"detail":   "volume vol-1 not found",
+		"code":     float64(83),
+		"category": "cli_extended",
+		"fields":   map[string]any{"volume_id": "vol-1"},
+	}
+	for k, v := range want {
+		got, _ := json.Marshal(body[k])
+		exp, _ := json.Marshal(v)
+		if string(got) != string(exp) {
+			t.Errorf("member %q = %s, want %s", k, got, exp)
+		}
+	}
+}
+
+func TestHandlerFunc_ServerErrorHidesCause(t *testing.T) {
+	err := WithCode(errors.New("pq: password authentication failed for user admin"), ExitCodeSoftware).
+		WithField("dsn", "postgres://admin@db")
+	rec, body := serveProblem(t, err)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+	for _, k := range []string{"detail", "fields"} {
+		if _, ok := body[k]; ok {
+			t.Errorf("5xx problem should not contain %q: %v", k, body)
+		}
+	}
+	if body["title"] != "Internal software error" {
+		t.Errorf("title = %v", body["title"])
+	}
+}
+
+func TestHandlerFunc_PlainErrorAndRetryAfter(t *testing.T) {
+	rec, _ := serveProblem(t, WithRetryAfter(NewExitError(ExitCodeRateLimit, "slow down", nil), 1500*time.Millisecond))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+
+	rec, body := serveProblem(t, errors.New("boom"))
+	if rec.Code != http.StatusInternalServerError || body["code"] != float64(1) {
+		t.Errorf("plain error: status = %d, body = %v", rec.Code, body)
+	}
+}
+
+func TestHandlerFunc_NoError(t *testing.T) {
+	h := HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusNoContent || rec.Body.Len() != 0 {
+		t.Errorf("status = %d, body = %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestWriteProblem_Nil(t *testing.T) {
+	if p := NewProblem(nil); p != nil {
+		t.Errorf("NewProblem(nil) = %+v, want nil", p)
+	}
+	rec := httptest.NewRecorder()
+	WriteProblem(rec, nil)
+	if rec.Body.Len() != 0 || len(rec.Header()) != 0 {
+		t.Errorf("header = %v, body = %q, want nothing written", rec.Header(), rec.Body.String())
+	}
+}
+
+func TestProblem_MarshalJSON_StandardMembersWin(t *testing.T) {
+	p := &Problem{Title: "Conflict", Status: 409, Extensions: map[string]any{"title": "x", "status": 1, "detail": "y"}}
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"status":409,"title":"Conflict"}` {
+		t.Errorf("json = %s", data)
+	}
+	if data, _ := json.Marshal(*p); string(data) != `{"status":409,"title":"Conflict"}` {
+		t.Errorf("json of value = %s", data)
+	}
+}
+
+func TestNewProblem_SuccessCode(t *testing.T) {
+	p := NewProblem(WithCode(errors.New("oops"), ExitCodeSuccess))
+	if p.Status != http.StatusInternalServerError || p.Type != "urn:cli:exit-code:1" || p.Detail != "" {
+		t.Errorf("problem = %+v, want a 500 without detail", p)
+	}
+}
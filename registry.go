@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// CodeInfo describes the metadata attached to an exit code.
+// All ExitCode methods (String, Category, IsRetriable, IsUserError) and
+// ToHTTPStatus read from the registry, so registered application codes
+// behave exactly like the built-in ones.
+type CodeInfo struct {
+	// Name is the human-readable description returned by ExitCode.String
+	Name string
+	// Category overrides the range-based category; empty means derive it from the code value
+	Category Category
+	// Retriable reports whether the operation may be retried
+	Retriable bool
+	// UserError reports whether the error is caused by incorrect user actions
+	UserError bool
+	// HTTPStatus is the recommended HTTP status; zero means 500
+	HTTPStatus int
+	// Severity ranks the code among joined errors; zero means derive it (see ExitCode.Severity)
+	Severity Severity
+	// GRPCCode is the recommended gRPC status code; zero means Unknown
+	// (Canceled for signal codes)
+	GRPCCode uint32
+}
+
+// Range available to application-defined codes: 0 is success and 128+ is
+// reserved for signal terminations
+const (
+	minCustomCode ExitCode = 1
+	maxCustomCode ExitCode = 127
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[ExitCode]CodeInfo{
+		ExitCodeSuccess:         {Name: "Success", HTTPStatus: 200},
+		ExitCodeErrorInternal:   {Name: "Internal error", HTTPStatus: 500, Severity: SeverityInternal, GRPCCode: GRPCCodeUnknown},
+		ExitCodeInvalidArgument: {Name: "Invalid argument", UserError: true, HTTPStatus: 400, GRPCCode: GRPCCodeInvalidArgument},
+		ExitCodeCmdUsage:        {Name: "Command usage error", UserError: true, HTTPStatus: 400, GRPCCode: GRPCCodeInvalidArgument},
+		ExitCodeDataError:       {Name: "Data format error", UserError: true, HTTPStatus: 400, GRPCCode: GRPCCodeInvalidArgument},
+		ExitCodeNoInput:         {Name: "Input file not found", UserError: true, HTTPStatus: 404, GRPCCode: GRPCCodeNotFound},
+		ExitCodeNoUser:          {Name: "User not found", UserError: true, GRPCCode: GRPCCodeNotFound},
+		ExitCodeNoHost:          {Name: "Host not found", UserError: true, GRPCCode: GRPCCodeUnavailable},
+		ExitCodeUnavailable:     {Name: "Service unavailable", Retriable: true, HTTPStatus: 503, GRPCCode: GRPCCodeUnavailable},
+		ExitCodeSoftware:        {Name: "Internal software error", HTTPStatus: 500, Severity: SeverityInternal, GRPCCode: GRPCCodeInternal},
+		ExitCodeOSError:         {Name: "Operating system error", HTTPStatus: 500, GRPCCode: GRPCCodeInternal},
+		ExitCodeOSFile:          {Name: "System file error", GRPCCode: GRPCCodeInternal},
+		ExitCodeCantCreate:      {Name: "Cannot create output file", GRPCCode: GRPCCodeInternal},
+		ExitCodeIOError:         {Name: "I/O error", Retriable: true, HTTPStatus: 500, GRPCCode: GRPCCodeInternal},
+		ExitCodeTempFail:        {Name: "Temporary failure", Retriable: true, HTTPStatus: 503, GRPCCode: GRPCCodeUnavailable},
+		ExitCodeProtocol:        {Name: "Protocol error", GRPCCode: GRPCCodeInternal},
+		ExitCodeNoPermission:    {Name: "Permission denied", UserError: true, HTTPStatus: 403, GRPCCode: GRPCCodePermissionDenied},
+		ExitCodeConfig:          {Name: "Configuration error", UserError: true, GRPCCode: GRPCCodeFailedPrecondition},
+		ExitCodeAuthRequired:    {Name: "Authentication required", HTTPStatus: 401, GRPCCode: GRPCCodeUnauthenticated},
+		ExitCodeAuthFailed:      {Name: "Authentication failed", HTTPStatus: 401, GRPCCode: GRPCCodeUnauthenticated},
+		ExitCodeForbidden:       {Name: "Forbidden", HTTPStatus: 403, GRPCCode: GRPCCodePermissionDenied},
+		ExitCodeNotFound:        {Name: "Not found", UserError: true, HTTPStatus: 404, GRPCCode: GRPCCodeNotFound},
+		ExitCodeConflict:        {Name: "Conflict", HTTPStatus: 409, GRPCCode: GRPCCodeAlreadyExists},
+		ExitCodeValidation:      {Name: "Validation error", UserError: true, HTTPStatus: 400, GRPCCode: GRPCCodeInvalidArgument},
+		ExitCodeRateLimit:       {Name: "Rate limit exceeded", Retriable: true, HTTPStatus: 429, GRPCCode: GRPCCodeResourceExhausted},
+		ExitCodeQuotaExceeded:   {Name: "Quota exceeded", HTTPStatus: 429, GRPCCode: GRPCCodeResourceExhausted},
+		ExitCodeCannotExecute:   {Name: "Cannot execute command", Category: CategoryGeneral, GRPCCode: GRPCCodeInternal},
+		ExitCodeCommandNotFound: {Name: "Command not found", Category: CategoryGeneral, UserError: true, GRPCCode: GRPCCodeUnimplemented},
+		ExitCodeHangup:          {Name: "Hangup"},
+		ExitCodeInterrupted:     {Name: "Interrupted by user"},
+		ExitCodeQuit:            {Name: "Quit by user"},
+		ExitCodeKilled:          {Name: "Killed"},
+		ExitCodeBrokenPipe:      {Name: "Broken pipe"},
+		ExitCodeTerminated:      {Name: "Terminated by system"},
+	}
+)
+
+// RegisterCode registers an application-defined exit code.
+// Codes must be in the range 1-127 and must not already be registered.
+func RegisterCode(code ExitCode, info CodeInfo) error {
+	if code < minCustomCode || code > maxCustomCode {
+		return fmt.Errorf("exit code %d out of range [%d, %d]", int(code), int(minCustomCode), int(maxCustomCode))
+	}
+	if info.Name == "" {
+		return fmt.Errorf("exit code %d: name is required", int(code))
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if existing, ok := registry[code]; ok {
+		return fmt.Errorf("exit code %d already registered as %q", int(code), existing.Name)
+	}
+	registry[code] = info
+	return nil
+}
+
+// MustRegisterCode is like RegisterCode but panics on error.
+// It is intended for use in package-level variable initialization.
+func MustRegisterCode(code ExitCode, info CodeInfo) ExitCode {
+	if err := RegisterCode(code, info); err != nil {
+		panic(err)
+	}
+	return code
+}
+
+// LookupCode returns the registered metadata for the code
+func LookupCode(code ExitCode) (CodeInfo, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	info, ok := registry[code]
+	return info, ok
+}
+
+// RegisteredCodes returns all registered codes in ascending order
+func RegisteredCodes() []ExitCode {
+	registryMu.RLock()
+	codes := make([]ExitCode, 0, len(registry))
+	for code := range registry {
+		codes = append(codes, code)
+	}
+	registryMu.RUnlock()
+
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
@@ -0,0 +1,95 @@
+package cli
+
+import "testing"
+
+// unregisterCode removes a code registered by a test
+func unregisterCode(t *testing.T, code ExitCode) {
+	t.Helper()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, code)
+		registryMu.Unlock()
+	})
+}
+
+func TestRegisterCode(t *testing.T) {
+	const migrationPending ExitCode = 90
+	unregisterCode(t, migrationPending)
+
+	err := RegisterCode(migrationPending, CodeInfo{
+		Name:       "Schema migration pending",
+		Retriable:  true,
+		UserError:  true,
+		HTTPStatus: 409,
+	})
+	if err != nil {
+		t.Fatalf("RegisterCode returned error: %v", err)
+	}
+
+	if got := migrationPending.String(); got != "Schema migration pending" {
+		t.Errorf("String() = %v, want %v", got, "Schema migration pending")
+	}
+	if got := migrationPending.Category(); got != CategoryCLIExtended {
+		t.Errorf("Category() = %v, want %v", got, CategoryCLIExtended)
+	}
+	if !migrationPending.IsRetriable() {
+		t.Error("custom code should be retriable")
+	}
+	if !migrationPending.IsUserError() {
+		t.Error("custom code should be a user error")
+	}
+	if got := ToHTTPStatus(migrationPending); got != 409 {
+		t.Errorf("ToHTTPStatus() = %d, want 409", got)
+	}
+}
+
+func TestRegisterCode_CategoryOverride(t *testing.T) {
+	const code ExitCode = 42
+	unregisterCode(t, code)
+
+	if err := RegisterCode(code, CodeInfo{Name: "Custom", Category: CategoryUserError}); err != nil {
+		t.Fatalf("RegisterCode returned error: %v", err)
+	}
+	if got := code.Category(); got != CategoryUserError {
+		t.Errorf("Category() = %v, want %v", got, CategoryUserError)
+	}
+	if got := ToHTTPStatus(code); got != 500 {
+		t.Errorf("ToHTTPStatus() = %d, want 500", got)
+	}
+}
+
+func TestRegisterCode_Rejects(t *testing.T) {
+	tests := []struct {
+		name string
+		code ExitCode
+		info CodeInfo
+	}{
+		{"success", ExitCodeSuccess, CodeInfo{Name: "x"}},
+		{"negative", ExitCode(-1), CodeInfo{Name: "x"}},
+		{"signal_range", ExitCode(150), CodeInfo{Name: "x"}},
+		{"out_of_range", ExitCode(300), CodeInfo{Name: "x"}},
+		{"builtin_duplicate", ExitCodeNotFound, CodeInfo{Name: "x"}},
+		{"empty_name", ExitCode(91), CodeInfo{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RegisterCode(tt.code, tt.info); err == nil {
+				t.Errorf("RegisterCode(%d) should return error", tt.code)
+			}
+		})
+	}
+}
+
+func TestRegisteredCodes(t *testing.T) {
+	codes := RegisteredCodes()
+	for i := 1; i < len(codes); i++ {
+		if codes[i-1] >= codes[i] {
+			t.Fatalf("RegisteredCodes not sorted: %v", codes)
+		}
+	}
+	info, ok := LookupCode(ExitCodeRateLimit)
+	if !ok || !info.Retriable || info.HTTPStatus != 429 {
+		t.Fatalf("LookupCode(RateLimit) = %+v, %v", info, ok)
+	}
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ColorMode selects when a Renderer uses ANSI colors
+type ColorMode int
+
+const (
+	// ColorAuto colors output written to a terminal when NO_COLOR is unset
+	ColorAuto ColorMode = iota
+	// ColorAlways always colors output
+	ColorAlways
+	// ColorNever never colors output
+	ColorNever
+)
+
+// ANSI escape sequences used by Renderer
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBoldRed = "\x1b[1;31m"
+	ansiDim     = "\x1b[2m"
+	ansiCyan    = "\x1b[36m"
+)
+
+// Renderer writes errors for humans in a consistent layout:
+//
+//	mycmd: error: missing endpoint
+//	  code: 78 (Configuration error)
+//	  category: user_error
+//	  hint: pass --endpoint or set MYCMD_ENDPOINT
+//	  caused by: read config.yaml: no "endpoint" key
+//
+// The output depends only on the error and the Renderer settings, so it can
+// be golden-tested. The zero value is ready to use.
+type Renderer struct {
+	// Program prefixes the first line. Defaults to the base name of os.Args[0].
+	Program string
+	// Causes adds one "caused by" line per wrapped cause (see ExitError.Format)
+	Causes bool
+	// Color selects when ANSI colors are used
+	Color ColorMode
+
+	// Output selects the human layout or JSON Lines events
+	Output OutputMode
+	// Version is the program version reported in JSON events
+	Version string
+	// Args are reported, with secrets redacted, in JSON events.
+	// Defaults to os.Args[1:].
+	Args []string
+	// Start is when the program started; JSON events report the duration
+	// since then when set
+	Start time.Time
+
+	// now overrides time.Now in tests
+	now func() time.Time
+}
+
+// Render writes err to w. The code is ResolveExitCode(err); hints are
+// collected from every ExitError in the chain, outermost first. A nil error
+// writes nothing.
+//
+// In OutputJSON mode a single line is written: the ExitError JSON wire format
+// (of err, or of err with its resolved code and the fields and hints of a
+// wrapped ExitError) extended with timestamp, program, program_version, args,
+// duration (seconds) and exit_code members. It decodes with
+// ExitError.UnmarshalJSON.
+func (r *Renderer) Render(w io.Writer, err error) error {
+	if err == nil {
+		return nil
+	}
+	if outputMode(r.Output) == OutputJSON {
+		return r.renderJSON(w, err)
+	}
+	color := r.useColor(w)
+	paint := func(style, s string) string {
+		if !color {
+			return s
+		}
+		return style + s + ansiReset
+	}
+
+	code := ResolveExitCode(err)
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s: %s %s\n", r.program(), paint(ansiBoldRed, "error:"), oneLine(err.Error()))
+	fmt.Fprintf(&b, "  %s %d (%s)\n", paint(ansiDim, "code:"), int(code), code)
+	fmt.Fprintf(&b, "  %s %s\n", paint(ansiDim, "category:"), code.Category())
+	for _, hint := range errorHints(err) {
+		fmt.Fprintf(&b, "  %s %s\n", paint(ansiCyan, "hint:"), hint)
+	}
+	if r.Causes {
+		writeCauses(&b, err, "  ")
+	}
+	_, wErr := w.Write(b.Bytes())
+	return wErr
+}
+
+func (r *Renderer) program() string {
+	if r.Program != "" {
+		return r.Program
+	}
+	return filepath.Base(os.Args[0])
+}
+
+func (r *Renderer) useColor(w io.Writer) bool {
+	switch r.Color {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	return os.Getenv("NO_COLOR") == "" && isTerminal(w)
+}
+
+// isTerminal reports whether w is a character device such as a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// errorHints collects the hints of every ExitError in err's tree, depth-first
+func errorHints(err error) []string {
+	var hints []string
+	if e, ok := err.(*ExitError); ok {
+		hints = append(hints, e.hints...)
+	}
+	for _, child := range unwrapAll(err) {
+		hints = append(hints, errorHints(child)...)
+	}
+	return hints
+}
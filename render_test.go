@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderer_Render(t *testing.T) {
+	cause := fmt.Errorf("read config.yaml: %w", errors.New(`no "endpoint" key`))
+	err := NewExitError(ExitCodeConfig, "missing endpoint", cause).
+		WithHint("pass --endpoint or set MYCMD_ENDPOINT")
+
+	tests := []struct {
+		name     string
+		renderer Renderer
+		err      error
+		want     string
+	}{
+		{
+			name:     "plain",
+			renderer: Renderer{Program: "mycmd"},
+			err:      err,
+			want: "mycmd: error: missing endpoint\n" +
+				"  code: 78 (Configuration error)\n" +
+				"  category: user_error\n" +
+				"  hint: pass --endpoint or set MYCMD_ENDPOINT\n",
+		},
+		{
+			name:     "causes",
+			renderer: Renderer{Program: "mycmd", Causes: true},
+			err:      err,
+			want: "mycmd: error: missing endpoint\n" +
+				"  code: 78 (Configuration error)\n" +
+				"  category: user_error\n" +
+				"  hint: pass --endpoint or set MYCMD_ENDPOINT\n" +
+				"  caused by: read config.yaml: no \"endpoint\" key\n" +
+				"  caused by: no \"endpoint\" key\n",
+		},
+		{
+			name:     "color",
+			renderer: Renderer{Program: "mycmd", Color: ColorAlways},
+			err:      err,
+			want: "mycmd: \x1b[1;31merror:\x1b[0m missing endpoint\n" +
+				"  \x1b[2mcode:\x1b[0m 78 (Configuration error)\n" +
+				"  \x1b[2mcategory:\x1b[0m user_error\n" +
+				"  \x1b[36mhint:\x1b[0m pass --endpoint or set MYCMD_ENDPOINT\n",
+		},
+		{
+			name:     "plain_error",
+			renderer: Renderer{Program: "mycmd"},
+			err:      fmt.Errorf("open state: %w", ErrNotFound),
+			want: "mycmd: error: open state: not found\n" +
+				"  code: 83 (Not found)\n" +
+				"  category: cli_extended\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := tt.renderer.Render(&b, tt.err); err != nil {
+				t.Fatalf("Render() error: %v", err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("Render() =\n%q\nwant\n%q", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderer_NilError(t *testing.T) {
+	var b bytes.Buffer
+	if err := (&Renderer{}).Render(&b, nil); err != nil || b.Len() != 0 {
+		t.Errorf("Render(nil) wrote %q, %v", b.String(), err)
+	}
+}
+
+func TestRenderer_HintsFromChain(t *testing.T) {
+	inner := NewExitError(ExitCodeAuthFailed, "token expired", nil).WithHint("run `mycmd login`")
+	outer := WithCode(fmt.Errorf("list: %w", inner), ExitCodeAuthFailed).WithHint("check your account")
+
+	if got := errorHints(outer); len(got) != 2 || got[0] != "check your account" || got[1] != "run `mycmd login`" {
+		t.Errorf("errorHints() = %q", got)
+	}
+}
+
+func TestRenderer_ColorDetection(t *testing.T) {
+	r := &Renderer{}
+	if r.useColor(&bytes.Buffer{}) {
+		t.Error("buffer should not be colored")
+	}
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if isTerminal(f) {
+		t.Error("regular file reported as terminal")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if (&Renderer{}).useColor(os.Stderr) {
+		t.Error("NO_COLOR should disable color")
+	}
+	if !(&Renderer{Color: ColorAlways}).useColor(&bytes.Buffer{}) {
+		t.Error("ColorAlways should enable color")
+	}
+}
+
+func TestRunner_Renderer(t *testing.T) {
+	var stderr bytes.Buffer
+	r := &Runner{
+		Stdout:   &bytes.Buffer{},
+		Stderr:   &stderr,
+		Exit:     func(int) {},
+		Renderer: &Renderer{Program: "mycmd"},
+	}
+	r.Run(func(context.Context) error { return NotFoundError("volume") })
+
+	want := "mycmd: error: volume not found\n  code: 83 (Not found)\n  category: cli_extended\n"
+	if stderr.String() != want {
+		t.Errorf("stderr = %q, want %q", stderr.String(), want)
+	}
+}
@@ -0,0 +1,107 @@
+package cli
+
+import "sync"
+
+// Resolver maps an error to an exit code.
+// Resolve reports false when it does not recognize the error, letting the
+// next resolver in the chain try.
+type Resolver interface {
+	Resolve(err error) (ExitCode, bool)
+}
+
+// ResolverFunc adapts an ordinary function to the Resolver interface
+type ResolverFunc func(err error) (ExitCode, bool)
+
+// Resolve calls f(err)
+func (f ResolverFunc) Resolve(err error) (ExitCode, bool) {
+	return f(err)
+}
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = DefaultResolvers()
+)
+
+// DefaultResolvers returns the built-in resolver chain in evaluation order:
+// ExitError, os/exec, gRPC status, database, ExitCode()/HTTPStatus()/StatusCode()
+// methods, signals, context, os, errno, network details, net, Timeout() method,
+// decoding, predefined errors
+func DefaultResolvers() []Resolver {
+	rs := make([]Resolver, 0, len(builtinRules)+1)
+	for _, r := range builtinRules {
+		rs = append(rs, r)
+	}
+	return append(rs, rule(resolveSentinel))
+}
+
+// builtinRules are the default rules checked before registered sentinels
+var builtinRules = []rule{
+	resolveExitError,
+	resolveExec,
+	resolveGRPC,
+	resolveSQL,
+	resolveStatusMethods,
+	resolveSignal,
+	resolveContext,
+	resolveOS,
+	resolveErrno,
+	resolveNetDetail,
+	resolveNet,
+	resolveTimeoutMethod,
+	resolveDecode,
+}
+
+// rule is a built-in resolver that explains which check matched
+type rule func(err error) (Resolution, bool)
+
+func (r rule) Resolve(err error) (ExitCode, bool) {
+	res, ok := r(err)
+	return res.Code, ok
+}
+
+func (r rule) explain(err error) (Resolution, bool) {
+	return r(err)
+}
+
+// NamedResolver gives a resolver the name reported by ExplainExitCode
+func NamedResolver(name string, r Resolver) Resolver {
+	return &namedResolver{name: name, Resolver: r}
+}
+
+type namedResolver struct {
+	name string
+	Resolver
+}
+
+func (r *namedResolver) Name() string { return r.name }
+
+// Resolvers returns a copy of the resolver chain used by ResolveExitCode
+func Resolvers() []Resolver {
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	return append([]Resolver(nil), resolvers...)
+}
+
+// SetResolvers replaces the resolver chain.
+// SetResolvers(DefaultResolvers()...) restores the built-in chain.
+func SetResolvers(rs ...Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers = append([]Resolver(nil), rs...)
+}
+
+// AppendResolvers adds resolvers to the end of the chain; they are consulted
+// only for errors no earlier resolver recognizes
+func AppendResolvers(rs ...Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers = append(append([]Resolver(nil), resolvers...), rs...)
+}
+
+// PrependResolvers adds resolvers ahead of the chain, so they take
+// precedence over the built-in mappings
+func PrependResolvers(rs ...Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers = append(append([]Resolver(nil), rs...), resolvers...)
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// restoreResolvers resets the resolver chain after a test modifies it
+func restoreResolvers(t *testing.T) {
+	t.Helper()
+	saved := Resolvers()
+	t.Cleanup(func() { SetResolvers(saved...) })
+}
+
+type dbError struct{ constraint string }
+
+func (e *dbError) Error() string { return "constraint violation: " + e.constraint }
+
+func resolveDBError(err error) (ExitCode, bool) {
+	var de *dbError
+	if errors.As(err, &de) {
+		return ExitCodeConflict, true
+	}
+	return 0, false
+}
+
+func TestAppendResolvers(t *testing.T) {
+	restoreResolvers(t)
+	AppendResolvers(ResolverFunc(resolveDBError))
+
+	err := fmt.Errorf("insert user: %w", &dbError{constraint: "users_email_key"})
+	if got := ResolveExitCode(err); got != ExitCodeConflict {
+		t.Errorf("ResolveExitCode() = %d, want %d", got, ExitCodeConflict)
+	}
+	// Built-in mappings still take precedence
+	if got := ResolveExitCode(ErrNotFound); got != ExitCodeNotFound {
+		t.Errorf("ResolveExitCode(ErrNotFound) = %d, want %d", got, ExitCodeNotFound)
+	}
+}
+
+func TestPrependResolvers(t *testing.T) {
+	restoreResolvers(t)
+	PrependResolvers(ResolverFunc(func(err error) (ExitCode, bool) {
+		if errors.Is(err, ErrNotFound) {
+			return ExitCodeNoInput, true
+		}
+		return 0, false
+	}))
+
+	if got := ResolveExitCode(NotFoundError("file")); got != ExitCodeNoInput {
+		t.Errorf("ResolveExitCode() = %d, want %d", got, ExitCodeNoInput)
+	}
+}
+
+func TestSetResolvers(t *testing.T) {
+	restoreResolvers(t)
+	SetResolvers(ResolverFunc(resolveDBError))
+
+	if got := ResolveExitCode(&dbError{}); got != ExitCodeConflict {
+		t.Errorf("ResolveExitCode() = %d, want %d", got, ExitCodeConflict)
+	}
+	// Without the defaults every other error falls back to internal error
+	if got := ResolveExitCode(ErrNotFound); got != ExitCodeErrorInternal {
+		t.Errorf("ResolveExitCode(ErrNotFound) = %d, want %d", got, ExitCodeErrorInternal)
+	}
+
+	SetResolvers(DefaultResolvers()...)
+	if got := ResolveExitCode(ErrNotFound); got != ExitCodeNotFound {
+		t.Errorf("ResolveExitCode(ErrNotFound) after reset = %d, want %d", got, ExitCodeNotFound)
+	}
+}
+
+func TestResolvers_ReturnsCopy(t *testing.T) {
+	rs := Resolvers()
+	rs[0] = nil
+	if Resolvers()[0] == nil {
+		t.Error("Resolvers() should return a copy of the chain")
+	}
+}
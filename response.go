@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxProblemBody limits how much of a response body FromHTTPResponse reads
+const maxProblemBody = 64 << 10
+
+// HTTPError describes an unsuccessful HTTP response.
+// It is the Cause of the ExitError returned by FromHTTPResponse.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	// Problem holds the parsed application/problem+json body, if any
+	Problem *Problem
+}
+
+func (e *HTTPError) Error() string {
+	if e.Problem != nil {
+		return fmt.Sprintf("HTTP %s: %v", e.Status, e.Problem)
+	}
+	return "HTTP " + e.Status
+}
+
+// Unwrap returns the parsed problem, if any
+func (e *HTTPError) Unwrap() error {
+	if e.Problem == nil {
+		return nil
+	}
+	return e.Problem
+}
+
+// FromHTTPResponse converts an unsuccessful response into an *ExitError.
+// It returns nil for statuses below 400.
+//
+// The code comes from FromHTTPStatus, or from the "code" member of a problem
+// body produced by WriteProblem (its type is ProblemTypePrefix + code) when
+// that code is registered. The message is
+// the problem detail or title, falling back to the status line. Retry-After
+// (seconds or HTTP-date) and rate-limit reset headers become a retry hint
+// honoured by Retry. Request and rate-limit details are attached as fields.
+//
+// Up to 64 KiB of a problem body is read; resp.Body is replaced so the
+// caller can still read the whole body, and must still close it.
+func FromHTTPResponse(resp *http.Response) error {
+	if resp == nil || resp.StatusCode < 400 {
+		return nil
+	}
+
+	httpErr := &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status, Problem: readProblem(resp)}
+	if httpErr.Status == "" {
+		httpErr.Status = strconv.Itoa(resp.StatusCode) + " " + http.StatusText(resp.StatusCode)
+	}
+
+	code := FromHTTPStatus(resp.StatusCode)
+	message := httpErr.Status
+	if p := httpErr.Problem; p != nil {
+		if c, ok := problemCode(p); ok {
+			code = c
+		}
+		if p.Detail != "" {
+			message = p.Detail
+		} else if p.Title != "" {
+			message = p.Title
+		}
+	}
+
+	var cause error = httpErr
+	delay, hinted := retryAfterHeader(resp.Header, time.Now())
+	if hinted {
+		cause = WithRetryAfter(cause, delay)
+	}
+
+	e := NewExitError(code, message, cause).WithField("http_status", resp.StatusCode)
+	if req := resp.Request; req != nil && req.URL != nil {
+		u := *req.URL
+		u.User, u.RawQuery, u.Fragment = nil, "", ""
+		e.WithField("method", req.Method).WithField("url", u.String())
+	}
+	if p := httpErr.Problem; p != nil && p.Type != "" && p.Type != "about:blank" {
+		e.WithField("problem_type", p.Type)
+	}
+	if hinted {
+		e.WithField("retry_after", delay.String())
+	}
+	for _, h := range []string{"RateLimit-Limit", "RateLimit-Remaining", "X-RateLimit-Limit", "X-RateLimit-Remaining"} {
+		if n, err := strconv.Atoi(resp.Header.Get(h)); err == nil {
+			key := strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(h, "X-"), "-", "_"))
+			e.WithField(key, n)
+		}
+	}
+	return e
+}
+
+// readProblem parses a problem+json body, leaving resp.Body readable
+func readProblem(resp *http.Response) *Problem {
+	if resp.Body == nil {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != ProblemContentType {
+		return nil
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxProblemBody))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+
+	var p Problem
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil
+	}
+	return &p
+}
+
+// problemCode returns the registered exit code carried in the "code" member
+// of a problem written by WriteProblem. "code" is a common vendor extension,
+// so it is only trusted when the type is ProblemTypePrefix followed by it.
+func problemCode(p *Problem) (ExitCode, bool) {
+	n, ok := p.Extensions["code"].(float64)
+	if !ok || n != float64(int(n)) {
+		return 0, false
+	}
+	code := ExitCode(n)
+	if p.Type != ProblemTypePrefix+strconv.Itoa(int(code)) {
+		return 0, false
+	}
+	if _, known := LookupCode(code); !known || code == ExitCodeSuccess {
+		return 0, false
+	}
+	return code, true
+}
+
+// retryAfterHeader extracts a retry hint from Retry-After or, when the rate
+// limit is exhausted, from the rate-limit reset headers
+func retryAfterHeader(h http.Header, now time.Time) (time.Duration, bool) {
+	if v := strings.TrimSpace(h.Get("Retry-After")); v != "" {
+		if secs, err := strconv.ParseInt(v, 10, 64); err == nil && secs >= 0 {
+			return deltaSeconds(secs), true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return max(t.Sub(now), 0), true
+		}
+	}
+
+	for _, prefix := range []string{"RateLimit-", "X-RateLimit-"} {
+		if h.Get(prefix+"Remaining") != "0" {
+			continue
+		}
+		reset, err := strconv.ParseInt(strings.TrimSpace(h.Get(prefix+"Reset")), 10, 64)
+		if err != nil || reset < 0 {
+			continue
+		}
+		// Large values are Unix timestamps (X-RateLimit-Reset), small ones delta seconds
+		if reset > 1e9 {
+			return max(time.Unix(reset, 0).Sub(now), 0), true
+		}
+		return deltaSeconds(reset), true
+	}
+	return 0, false
+}
+
+// maxDeltaSeconds is the longest hint a time.Duration can hold
+const maxDeltaSeconds = int64(math.MaxInt64 / time.Second)
+
+// deltaSeconds converts a non-negative delta-seconds header value, clamping
+// values that would overflow time.Duration
+func deltaSeconds(secs int64) time.Duration {
+	return time.Duration(min(secs, maxDeltaSeconds)) * time.Second
+}
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newResponse(status int, header http.Header, body string) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	req := httptest.NewRequest(http.MethodGet, "https://user:pw@api.example.com/v1/items?token=secret", nil)
+	return &http.Response{
+		StatusCode: status,
+		Status:     strconv.Itoa(status) + " " + http.StatusText(status),
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+}
+
+func TestFromHTTPResponse_Success(t *testing.T) {
+	for _, status := range []int{200, 204, 304} {
+		if err := FromHTTPResponse(newResponse(status, nil, "")); err != nil {
+			t.Errorf("FromHTTPResponse(%d) = %v, want nil", status, err)
+		}
+	}
+	if err := FromHTTPResponse(nil); err != nil {
+		t.Errorf("FromHTTPResponse(nil) = %v, want nil", err)
+	}
+}
+
+func TestFromHTTPResponse_PlainStatus(t *testing.T) {
+	err := FromHTTPResponse(newResponse(404, nil, "not here"))
+	var ee *ExitError
+	if !errors.As(err, &ee) || ee.Code != ExitCodeNotFound {
+		t.Fatalf("FromHTTPResponse() = %v, want NotFound ExitError", err)
+	}
+	if ee.Error() != "404 Not Found" {
+		t.Errorf("message = %q", ee.Error())
+	}
+	if v, _ := ee.Field("url"); v != "https://api.example.com/v1/items" {
+		t.Errorf("url field = %v, want credentials and query stripped", v)
+	}
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != 404 {
+		t.Errorf("cause = %v, want HTTPError", ee.Cause)
+	}
+}
+
+func TestFromHTTPResponse_Problem(t *testing.T) {
+	body := `{"type":"urn:cli:exit-code:87","title":"Quota exceeded","status":403,"detail":"project p-1 used 100 of 100 volumes","code":87}`
+	resp := newResponse(403, http.Header{"Content-Type": {"application/problem+json; charset=utf-8"}}, body)
+	err := FromHTTPResponse(resp)
+
+	var ee *ExitError
+	if !errors.As(err, &ee) {
+		t.Fatalf("FromHTTPResponse() = %v", err)
+	}
+	if ee.Code != ExitCodeQuotaExceeded {
+		t.Errorf("code = %d, want problem code %d", ee.Code, ExitCodeQuotaExceeded)
+	}
+	if ee.Error() != "project p-1 used 100 of 100 volumes" {
+		t.Errorf("message = %q", ee.Error())
+	}
+	if v, _ := ee.Field("problem_type"); v != "urn:cli:exit-code:87" {
+		t.Errorf("problem_type field = %v", v)
+	}
+	var p *Problem
+	if !errors.As(err, &p) || p.Title != "Quota exceeded" {
+		t.Errorf("problem not reachable through error chain: %v", p)
+	}
+
+	// Body is still readable by the caller
+	rest, _ := io.ReadAll(resp.Body)
+	if string(rest) != body {
+		t.Errorf("body after FromHTTPResponse = %q", rest)
+	}
+}
+
+func TestFromHTTPResponse_RetryAfter(t *testing.T) {
+	err := FromHTTPResponse(newResponse(429, http.Header{"Retry-After": {"7"}}, ""))
+	if got := ResolveExitCode(err); got != ExitCodeRateLimit {
+		t.Errorf("code = %d, want %d", got, ExitCodeRateLimit)
+	}
+	if d, ok := RetryAfter(err); !ok || d != 7*time.Second {
+		t.Errorf("RetryAfter() = %v, %v, want 7s", d, ok)
+	}
+
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	err = FromHTTPResponse(newResponse(503, http.Header{"Retry-After": {date}}, ""))
+	if d, ok := RetryAfter(err); !ok || d < 59*time.Minute || d > time.Hour {
+		t.Errorf("RetryAfter(HTTP-date) = %v, %v, want about 1h", d, ok)
+	}
+}
+
+func TestRetryAfterHeader_RateLimit(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		ok     bool
+	}{
+		{"delta", http.Header{"Ratelimit-Remaining": {"0"}, "Ratelimit-Reset": {"30"}}, 30 * time.Second, true},
+		{"epoch", http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"1700000045"}}, 45 * time.Second, true},
+		{"not_exhausted", http.Header{"X-Ratelimit-Remaining": {"5"}, "X-Ratelimit-Reset": {"30"}}, 0, false},
+		{"retry_after_wins", http.Header{"Retry-After": {"2"}, "Ratelimit-Remaining": {"0"}, "Ratelimit-Reset": {"30"}}, 2 * time.Second, true},
+		{"none", http.Header{}, 0, false},
+		{"huge", http.Header{"Retry-After": {"99999999999"}}, time.Duration(maxDeltaSeconds) * time.Second, true},
+		{"would_wrap", http.Header{"Retry-After": {"9300000000"}}, time.Duration(maxDeltaSeconds) * time.Second, true},
+		{"negative", http.Header{"Retry-After": {"-5"}}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfterHeader(tt.header, now)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("retryAfterHeader() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestFromHTTPResponse_RateLimitFields(t *testing.T) {
+	h := http.Header{"X-Ratelimit-Limit": {"100"}, "X-Ratelimit-Remaining": {"0"}}
+	err := FromHTTPResponse(newResponse(429, h, ""))
+	var ee *ExitError
+	errors.As(err, &ee)
+	if v, _ := ee.Field("ratelimit_limit"); v != 100 {
+		t.Errorf("ratelimit_limit = %v", v)
+	}
+	if v, _ := ee.Field("ratelimit_remaining"); v != 0 {
+		t.Errorf("ratelimit_remaining = %v", v)
+	}
+}
+
+func TestFromHTTPResponse_VendorCodeIgnored(t *testing.T) {
+	h := http.Header{"Content-Type": {ProblemContentType}}
+	for _, body := range []string{
+		`{"title":"Service Unavailable","status":503,"code":1}`,
+		`{"type":"https://example.com/errors/maintenance","status":503,"code":87}`,
+		`{"type":"urn:cli:exit-code:87","status":503,"code":1}`,
+	} {
+		err := FromHTTPResponse(newResponse(503, h, body))
+		if got := ResolveExitCode(err); got != ExitCodeUnavailable {
+			t.Errorf("body %s: code = %d, want %d from the status", body, got, ExitCodeUnavailable)
+		}
+	}
+}
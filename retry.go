@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy configures Retry. Zero-valued fields fall back to
+// DefaultRetryPolicy, except Jitter and MaxElapsed where zero disables them.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of calls, including the first one
+	MaxAttempts int
+	// InitialDelay is the delay before the second attempt
+	InitialDelay time.Duration
+	// MaxDelay caps the exponential backoff
+	MaxDelay time.Duration
+	// MaxRetryAfter caps the wait a retry-after hint may ask for; Retry
+	// gives up instead of waiting longer
+	MaxRetryAfter time.Duration
+	// Multiplier grows the delay after each attempt
+	Multiplier float64
+	// Jitter randomizes each delay by up to ±Jitter of its value (0..1)
+	Jitter float64
+	// MaxElapsed is the total time budget; Retry gives up instead of
+	// sleeping past it
+	MaxElapsed time.Duration
+	// Retriable overrides ExitCode.IsRetriable when deciding whether to retry
+	Retriable func(code ExitCode) bool
+}
+
+// DefaultRetryPolicy is the policy used for zero-valued RetryPolicy fields
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:   3,
+	InitialDelay:  100 * time.Millisecond,
+	MaxDelay:      10 * time.Second,
+	MaxRetryAfter: time.Minute,
+	Multiplier:    2,
+	Jitter:        0.2,
+}
+
+// RetryError records the outcome of a Retry that gave up
+type RetryError struct {
+	// Attempts is the number of times the operation was called
+	Attempts int
+	// Err is the last error returned by the operation
+	Err error
+	// Context is the context error if retrying was aborted by cancellation
+	Context error
+}
+
+func (e *RetryError) Error() string {
+	if e.Context != nil {
+		return fmt.Sprintf("retry aborted after %d attempts: %v (last error: %v)", e.Attempts, e.Context, e.Err)
+	}
+	return fmt.Sprintf("giving up after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() []error {
+	if e.Context != nil {
+		return []error{e.Context, e.Err}
+	}
+	return []error{e.Err}
+}
+
+// Retry calls fn until it succeeds, fails with a non-retriable error, or the
+// policy is exhausted. Non-retriable errors are returned unchanged. When
+// retries are exhausted or ctx is cancelled, Retry returns an *ExitError
+// whose Cause is a *RetryError recording the attempt count and last error.
+// Errors carrying a retry-after hint (see WithRetryAfter) wait for the hinted
+// duration instead of the computed backoff, up to MaxRetryAfter.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	p := policy.withDefaults()
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		code := ResolveExitCode(err)
+		if !p.retriable(code) {
+			return err
+		}
+		if attempt >= p.MaxAttempts {
+			return giveUp(code, &RetryError{Attempts: attempt, Err: err})
+		}
+
+		delay, hinted := RetryAfter(err)
+		if !hinted {
+			delay = p.backoff(attempt)
+		}
+		if hinted && delay > p.MaxRetryAfter || p.MaxElapsed > 0 && time.Since(start)+delay > p.MaxElapsed {
+			return giveUp(code, &RetryError{Attempts: attempt, Err: err})
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			cause := context.Cause(ctx)
+			return giveUp(ResolveExitCode(cause), &RetryError{Attempts: attempt, Err: err, Context: cause})
+		case <-timer.C:
+		}
+	}
+}
+
+func giveUp(code ExitCode, re *RetryError) *ExitError {
+	return NewExitError(code, re.Error(), re).WithField("attempts", re.Attempts)
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.InitialDelay <= 0 {
+		p.InitialDelay = DefaultRetryPolicy.InitialDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	if p.MaxRetryAfter <= 0 {
+		p.MaxRetryAfter = DefaultRetryPolicy.MaxRetryAfter
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = DefaultRetryPolicy.Multiplier
+	}
+	return p
+}
+
+func (p RetryPolicy) retriable(code ExitCode) bool {
+	if p.Retriable != nil {
+		return p.Retriable(code)
+	}
+	return code.IsRetriable()
+}
+
+// backoff returns the delay after the given (1-based) attempt
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.InitialDelay)
+	for i := 1; i < attempt && d < float64(p.MaxDelay); i++ {
+		d *= p.Multiplier
+	}
+	if d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+// retryAfterError attaches a retry-after hint to an error
+type retryAfterError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *retryAfterError) Error() string             { return e.err.Error() }
+func (e *retryAfterError) Unwrap() error             { return e.err }
+func (e *retryAfterError) RetryAfter() time.Duration { return e.after }
+
+// WithRetryAfter attaches a hint telling Retry how long to wait before the next attempt
+func WithRetryAfter(err error, d time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &retryAfterError{err: err, after: d}
+}
+
+// RetryAfter returns the retry-after hint carried anywhere in the error chain.
+// Any error implementing RetryAfter() time.Duration provides a hint.
+func RetryAfter(err error) (time.Duration, bool) {
+	var h interface{ RetryAfter() time.Duration }
+	if errors.As(err, &h) {
+		return h.RetryAfter(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var fastPolicy = RetryPolicy{MaxAttempts: 4, InitialDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+
+func TestRetry_SucceedsAfterTransientErrors(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), fastPolicy, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return TempFailError("flaky")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Retry returned error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestRetry_NonRetriableReturnedUnchanged(t *testing.T) {
+	want := ValidationError("bad input")
+	calls := 0
+	err := Retry(context.Background(), fastPolicy, func(context.Context) error {
+		calls++
+		return want
+	})
+	if err != want {
+		t.Errorf("Retry() = %v, want original error", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestRetry_Exhausted(t *testing.T) {
+	last := TempFailError("still down")
+	err := Retry(context.Background(), fastPolicy, func(context.Context) error {
+		return last
+	})
+
+	var ee *ExitError
+	if !errors.As(err, &ee) || ee.Code != ExitCodeTempFail {
+		t.Fatalf("Retry() = %v, want ExitError with TempFail", err)
+	}
+	var re *RetryError
+	if !errors.As(err, &re) {
+		t.Fatal("Retry() should wrap a RetryError")
+	}
+	if re.Attempts != 4 {
+		t.Errorf("Attempts = %d, want 4", re.Attempts)
+	}
+	if v, _ := ee.Field("attempts"); v != 4 {
+		t.Errorf("Field(attempts) = %v, want 4", v)
+	}
+	if !errors.Is(err, last) {
+		t.Error("Retry() should wrap the last error")
+	}
+}
+
+func TestRetry_RetriableOverride(t *testing.T) {
+	calls := 0
+	policy := fastPolicy
+	policy.Retriable = func(code ExitCode) bool { return code == ExitCodeConflict }
+	_ = Retry(context.Background(), policy, func(context.Context) error {
+		calls++
+		return NewExitError(ExitCodeConflict, "busy", nil)
+	})
+	if calls != 4 {
+		t.Errorf("calls = %d, want 4", calls)
+	}
+}
+
+func TestRetry_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := RetryPolicy{MaxAttempts: 5, InitialDelay: time.Hour}
+	err := Retry(ctx, policy, func(context.Context) error {
+		cancel()
+		return TempFailError("down")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Retry() = %v, want context.Canceled in chain", err)
+	}
+	if got := ResolveExitCode(err); got != ExitCodeInterrupted {
+		t.Errorf("ResolveExitCode() = %d, want %d", got, ExitCodeInterrupted)
+	}
+}
+
+func TestRetry_MaxElapsed(t *testing.T) {
+	calls := 0
+	policy := RetryPolicy{MaxAttempts: 10, InitialDelay: time.Hour, MaxElapsed: time.Second}
+	err := Retry(context.Background(), policy, func(context.Context) error {
+		calls++
+		return TempFailError("down")
+	})
+	if calls != 1 || err == nil {
+		t.Errorf("calls = %d, err = %v; want a single attempt and an error", calls, err)
+	}
+}
+
+func TestRetry_RetryAfterHint(t *testing.T) {
+	calls := 0
+	policy := RetryPolicy{MaxAttempts: 2, InitialDelay: time.Hour}
+	start := time.Now()
+	err := Retry(context.Background(), policy, func(context.Context) error {
+		calls++
+		if calls == 1 {
+			return WithRetryAfter(NewExitError(ExitCodeRateLimit, "slow down", nil), 5*time.Millisecond)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Retry returned error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Minute {
+		t.Errorf("Retry waited %v, want retry-after hint to be used", elapsed)
+	}
+}
+
+func TestRetry_LongRetryAfterHint(t *testing.T) {
+	calls := 0
+	policy := RetryPolicy{MaxAttempts: 2, MaxRetryAfter: time.Second}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := Retry(ctx, policy, func(context.Context) error {
+		calls++
+		return WithRetryAfter(NewExitError(ExitCodeRateLimit, "slow down", nil), 24*time.Hour)
+	})
+	var re *RetryError
+	if calls != 1 || !errors.As(err, &re) || re.Context != nil {
+		t.Errorf("calls = %d, err = %v; want to give up after one attempt without waiting", calls, err)
+	}
+	if got := ResolveExitCode(err); got != ExitCodeRateLimit {
+		t.Errorf("ResolveExitCode() = %d, want %d", got, ExitCodeRateLimit)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{InitialDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond, Multiplier: 2}.withDefaults()
+	want := []time.Duration{10, 20, 40, 50, 50}
+	for i, w := range want {
+		if got := p.backoff(i + 1); got != w*time.Millisecond {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w*time.Millisecond)
+		}
+	}
+}
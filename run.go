@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"time"
+)
+
+// MainFunc is the body of a command run by Run
+type MainFunc func(ctx context.Context) error
+
+// Runner owns the process lifecycle of a command: it creates a signal-aware
+// context, recovers panics, renders the error, flushes output and exits
+// with the resolved code. The zero value is ready to use.
+type Runner struct {
+	// Signals cancel the context passed to the main function.
+	// Defaults to os.Interrupt, SIGTERM and SIGHUP.
+	Signals []os.Signal
+	// Stdout is flushed before exit. Defaults to os.Stdout.
+	Stdout io.Writer
+	// Stderr receives the rendered error. Defaults to os.Stderr.
+	Stderr io.Writer
+	// Renderer formats the error on Stderr. Nil prints "Error: <message>",
+	// or a JSON event when $CLI_OUTPUT is "json".
+	Renderer *Renderer
+	// Exit terminates the process. Defaults to os.Exit; override in tests.
+	Exit func(code int)
+	// CrashDir receives a crash report when main panics (see
+	// WriteCrashReport). Defaults to $CLI_CRASH_DIR; empty disables reports.
+	CrashDir string
+}
+
+// Run executes main with the default Runner and exits the process.
+// It is the standard entry point for commands:
+//
+//	func main() {
+//		cli.Run(run)
+//	}
+func Run(main MainFunc) {
+	(&Runner{}).Run(main)
+}
+
+// Run executes main and exits with the code resolved from its error
+func (r *Runner) Run(main MainFunc) {
+	start := time.Now()
+	ctx, stop := NotifyContext(context.Background(), r.signals()...)
+	err := r.call(ctx, main)
+	// Attribute a plain context.Canceled to the signal that caused it
+	if se, ok := signalCause(ctx); ok && errors.Is(err, context.Canceled) && !errors.As(err, new(*SignalError)) {
+		err = &SignalError{Signal: se.Signal, Err: err}
+	}
+	stop()
+
+	res := ExplainExitCode(err)
+	note := r.reportCrash(err)
+	r.render(err, start)
+	if note != "" {
+		fmt.Fprintln(r.stderr(), note)
+	}
+	traceResolution(r.stderr(), res)
+	flush(r.stdout())
+	flush(r.stderr())
+	r.exit(int(res.Code))
+}
+
+// call invokes main converting a panic into an ExitCodeSoftware error
+func (r *Runner) call(ctx context.Context, main MainFunc) (err error) {
+	defer Recover(&err)
+	return main(ctx)
+}
+
+// render writes err to Stderr
+func (r *Runner) render(err error, start time.Time) {
+	if err == nil {
+		return
+	}
+	var renderer Renderer
+	switch {
+	case r.Renderer != nil:
+		renderer = *r.Renderer
+	case r.jsonOutput():
+		renderer.Output = OutputJSON
+	default:
+		fmt.Fprintf(r.stderr(), "Error: %v\n", err)
+		return
+	}
+	if renderer.Start.IsZero() {
+		renderer.Start = start
+	}
+	_ = renderer.Render(r.stderr(), err)
+}
+
+// reportCrash writes a crash report when err is a recovered panic and
+// records its path in the crash_report field. It returns a note for the user
+// to print after the error, empty in JSON output where the field carries it.
+func (r *Runner) reportCrash(err error) string {
+	var pe *PanicError
+	dir := r.crashDir()
+	if dir == "" || !errors.As(err, &pe) {
+		return ""
+	}
+	var exitErr *ExitError
+	hasField := errors.As(err, &exitErr)
+	path, wErr := WriteCrashReport(dir, pe)
+	if wErr != nil {
+		if r.jsonOutput() && hasField {
+			exitErr.WithField("crash_report_error", wErr.Error())
+			return ""
+		}
+		return fmt.Sprintf("cannot write crash report: %v", wErr)
+	}
+	if hasField {
+		exitErr.WithField("crash_report", path)
+	}
+	if r.jsonOutput() && hasField {
+		return ""
+	}
+	return "Crash report written to " + path
+}
+
+// jsonOutput reports whether render writes a JSON event
+func (r *Runner) jsonOutput() bool {
+	if r.Renderer != nil {
+		return outputMode(r.Renderer.Output) == OutputJSON
+	}
+	return outputMode(OutputDefault) == OutputJSON
+}
+
+func (r *Runner) signals() []os.Signal {
+	if len(r.Signals) > 0 {
+		return r.Signals
+	}
+	return []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP}
+}
+
+func (r *Runner) crashDir() string {
+	if r.CrashDir != "" {
+		return r.CrashDir
+	}
+	return os.Getenv(CrashDirEnv)
+}
+
+func (r *Runner) stdout() io.Writer {
+	if r.Stdout != nil {
+		return r.Stdout
+	}
+	return os.Stdout
+}
+
+func (r *Runner) stderr() io.Writer {
+	if r.Stderr != nil {
+		return r.Stderr
+	}
+	return os.Stderr
+}
+
+func (r *Runner) exit(code int) {
+	if r.Exit != nil {
+		r.Exit(code)
+		return
+	}
+	os.Exit(code)
+}
+
+// flush writes out buffered data; errors are ignored because there is
+// nowhere left to report them
+func flush(w io.Writer) {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		_ = f.Flush()
+	case interface{ Sync() error }:
+		_ = f.Sync()
+	}
+}
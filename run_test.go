@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// runForTest runs main with a Runner that captures the exit code and stderr
+func runForTest(t *testing.T, main MainFunc) (int, string) {
+	t.Helper()
+	var stderr bytes.Buffer
+	code := -1
+	r := &Runner{
+		Stdout: &bytes.Buffer{},
+		Stderr: &stderr,
+		Exit:   func(c int) { code = c },
+	}
+	r.Run(main)
+	return code, stderr.String()
+}
+
+func TestRunner_Success(t *testing.T) {
+	code, stderr := runForTest(t, func(ctx context.Context) error {
+		if ctx == nil {
+			t.Fatal("context should not be nil")
+		}
+		return nil
+	})
+	if code != 0 {
+		t.Errorf("exit code = %d, want 0", code)
+	}
+	if stderr != "" {
+		t.Errorf("stderr = %q, want empty", stderr)
+	}
+}
+
+func TestRunner_Error(t *testing.T) {
+	code, stderr := runForTest(t, func(context.Context) error {
+		return NotFoundError("config file")
+	})
+	if code != int(ExitCodeNotFound) {
+		t.Errorf("exit code = %d, want %d", code, ExitCodeNotFound)
+	}
+	if stderr != "Error: config file not found\n" {
+		t.Errorf("stderr = %q", stderr)
+	}
+}
+
+func TestRunner_Panic(t *testing.T) {
+	cause := errors.New("boom")
+	code, stderr := runForTest(t, func(context.Context) error {
+		panic(cause)
+	})
+	if code != int(ExitCodeSoftware) {
+		t.Errorf("exit code = %d, want %d", code, ExitCodeSoftware)
+	}
+	if !strings.Contains(stderr, "panic: boom") {
+		t.Errorf("stderr = %q, want panic message", stderr)
+	}
+}
+
+func TestRunner_FlushesStdout(t *testing.T) {
+	var out bytes.Buffer
+	w := bufio.NewWriter(&out)
+	r := &Runner{Stdout: w, Stderr: &bytes.Buffer{}, Exit: func(int) {}}
+	r.Run(func(context.Context) error {
+		_, err := w.WriteString("result\n")
+		return err
+	})
+	if out.String() != "result\n" {
+		t.Errorf("stdout = %q, want flushed output", out.String())
+	}
+}
+
+func TestRunner_SignalExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending signals to self is not supported on windows")
+	}
+	code := -1
+	r := &Runner{
+		Signals: []os.Signal{syscall.SIGHUP},
+		Stderr:  &bytes.Buffer{},
+		Exit:    func(c int) { code = c },
+	}
+	r.Run(func(ctx context.Context) error {
+		p, err := os.FindProcess(os.Getpid())
+		if err != nil {
+			return err
+		}
+		if err := p.Signal(syscall.SIGHUP); err != nil {
+			return err
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if code != int(ExitCodeHangup) {
+		t.Errorf("exit code = %d, want %d", code, ExitCodeHangup)
+	}
+}
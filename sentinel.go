@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// SentinelMapping associates a sentinel error with an exit code
+type SentinelMapping struct {
+	Err  error
+	Code ExitCode
+}
+
+var (
+	sentinelsMu sync.RWMutex
+	// Checked in order; the first sentinel matched by errors.Is wins
+	sentinels = []SentinelMapping{
+		{ErrInternal, ExitCodeErrorInternal},
+		{ErrInvalid, ExitCodeInvalidArgument},
+		{ErrUsage, ExitCodeUsageError},
+		{ErrDataFormat, ExitCodeDataError},
+		{ErrNotFound, ExitCodeNotFound},
+		{ErrNoPermission, ExitCodeNoPermission},
+		{ErrConfig, ExitCodeConfig},
+		{ErrAuth, ExitCodeAuthFailed},
+		{ErrForbidden, ExitCodeForbidden},
+		{ErrValidation, ExitCodeValidation},
+		{ErrIO, ExitCodeIOError},
+		{ErrUnavailable, ExitCodeUnavailable},
+		{ErrTempFail, ExitCodeTempFail},
+	}
+)
+
+// RegisterSentinel maps a sentinel error to an exit code: any error matching
+// it with errors.Is resolves to code, which must be a failure code (1-255).
+// Sentinels are checked in registration order after the predefined ones.
+// Registering the same mapping again is a no-op; mapping an already
+// registered sentinel to another code is an error, as is a sentinel a
+// built-in rule already resolves (sql.ErrNoRows, os.ErrNotExist, ...), since
+// that rule runs first; put a Resolver ahead of it with SetResolvers instead.
+// It is safe to call from concurrent init functions.
+func RegisterSentinel(err error, code ExitCode) error {
+	if err == nil {
+		return errors.New("sentinel error must not be nil")
+	}
+	if !reflect.TypeOf(err).Comparable() {
+		return fmt.Errorf("sentinel error %q has non-comparable type %T", err, err)
+	}
+	if code <= ExitCodeSuccess || code > 255 {
+		return fmt.Errorf("sentinel error %q: exit code %d out of range [1, 255]", err, int(code))
+	}
+	for _, r := range builtinRules {
+		if res, ok := r(err); ok {
+			return fmt.Errorf("sentinel error %q is already resolved by rule %s to exit code %d", err, res.Rule, int(res.Code))
+		}
+	}
+
+	sentinelsMu.Lock()
+	defer sentinelsMu.Unlock()
+	for _, m := range sentinels {
+		if m.Err != err {
+			continue
+		}
+		if m.Code == code {
+			return nil
+		}
+		return fmt.Errorf("sentinel error %q already registered with exit code %d", err, int(m.Code))
+	}
+	sentinels = append(sentinels, SentinelMapping{Err: err, Code: code})
+	return nil
+}
+
+// MustRegisterSentinel is like RegisterSentinel but panics on error
+func MustRegisterSentinel(err error, code ExitCode) {
+	if rErr := RegisterSentinel(err, code); rErr != nil {
+		panic(rErr)
+	}
+}
+
+// Sentinels returns all registered sentinel mappings in evaluation order
+func Sentinels() []SentinelMapping {
+	sentinelsMu.RLock()
+	defer sentinelsMu.RUnlock()
+	return append([]SentinelMapping(nil), sentinels...)
+}
+
+// resolveSentinel maps errors matching a registered sentinel
+func resolveSentinel(err error) (Resolution, bool) {
+	for _, m := range Sentinels() {
+		if errors.Is(err, m.Err) {
+			return matchSentinel(err, m.Err, m.Code, fmt.Sprintf("sentinel %q", m.Err)), true
+		}
+	}
+	return Resolution{}, false
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+)
+
+// restoreSentinels resets the sentinel registry after a test modifies it
+func restoreSentinels(t *testing.T) {
+	t.Helper()
+	saved := Sentinels()
+	t.Cleanup(func() {
+		sentinelsMu.Lock()
+		sentinels = saved
+		sentinelsMu.Unlock()
+	})
+}
+
+type nonComparableErr struct{ fields []string }
+
+func (nonComparableErr) Error() string { return "non-comparable" }
+
+func TestRegisterSentinel(t *testing.T) {
+	restoreSentinels(t)
+	errMigrationPending := errors.New("migration pending")
+
+	if err := RegisterSentinel(errMigrationPending, ExitCodeConflict); err != nil {
+		t.Fatalf("RegisterSentinel returned error: %v", err)
+	}
+	wrapped := fmt.Errorf("apply: %w", errMigrationPending)
+	if got := ResolveExitCode(wrapped); got != ExitCodeConflict {
+		t.Errorf("ResolveExitCode() = %d, want %d", got, ExitCodeConflict)
+	}
+
+	// Same mapping again is a no-op
+	if err := RegisterSentinel(errMigrationPending, ExitCodeConflict); err != nil {
+		t.Errorf("duplicate identical registration returned error: %v", err)
+	}
+	// Conflicting mapping is rejected
+	if err := RegisterSentinel(errMigrationPending, ExitCodeTempFail); err == nil {
+		t.Error("conflicting registration should return error")
+	}
+	if err := RegisterSentinel(ErrNotFound, ExitCodeNoInput); err == nil {
+		t.Error("remapping a predefined sentinel should return error")
+	}
+}
+
+func TestRegisterSentinel_Invalid(t *testing.T) {
+	restoreSentinels(t)
+	if err := RegisterSentinel(nil, ExitCodeConflict); err == nil {
+		t.Error("nil sentinel should return error")
+	}
+	if err := RegisterSentinel(nonComparableErr{}, ExitCodeConflict); err == nil {
+		t.Error("non-comparable sentinel should return error")
+	}
+	sentinel := errors.New("invalid code")
+	for _, code := range []ExitCode{ExitCodeSuccess, -1, 256} {
+		if err := RegisterSentinel(sentinel, code); err == nil {
+			t.Errorf("exit code %d should return error", code)
+		}
+	}
+	if got := ResolveExitCode(sentinel); got != ExitCodeError {
+		t.Errorf("ResolveExitCode = %d, want %d", got, ExitCodeError)
+	}
+	for _, builtin := range []error{sql.ErrNoRows, os.ErrNotExist, context.Canceled} {
+		want := ResolveExitCode(builtin)
+		if err := RegisterSentinel(builtin, ExitCodeNoInput); err == nil {
+			t.Errorf("sentinel %q resolved by a built-in rule should return error", builtin)
+		}
+		if got := ResolveExitCode(builtin); got != want {
+			t.Errorf("ResolveExitCode(%q) = %d, want %d", builtin, got, want)
+		}
+	}
+}
+
+func TestRegisterSentinel_Concurrent(t *testing.T) {
+	restoreSentinels(t)
+	before := len(Sentinels())
+
+	errs := make([]error, 50)
+	for i := range errs {
+		errs[i] = fmt.Errorf("sentinel %d", i)
+	}
+	var wg sync.WaitGroup
+	for _, e := range errs {
+		wg.Add(1)
+		go func(e error) {
+			defer wg.Done()
+			MustRegisterSentinel(e, ExitCodeValidation)
+		}(e)
+	}
+	wg.Wait()
+
+	if got := len(Sentinels()); got != before+len(errs) {
+		t.Errorf("len(Sentinels()) = %d, want %d", got, before+len(errs))
+	}
+	for _, e := range errs {
+		if got := ResolveExitCode(e); got != ExitCodeValidation {
+			t.Errorf("ResolveExitCode(%v) = %d, want %d", e, got, ExitCodeValidation)
+		}
+	}
+}
+
+func TestSentinels_Predefined(t *testing.T) {
+	found := false
+	for _, m := range Sentinels() {
+		if m.Err == ErrIO {
+			found = m.Code == ExitCodeIOError
+		}
+	}
+	if !found {
+		t.Error("Sentinels() should list ErrIO -> ExitCodeIOError")
+	}
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+)
+
+// Severity orders exit codes by how serious the failure is.
+// It decides which code wins when a joined error holds several failures.
+type Severity int
+
+const (
+	// SeverityNone successful completion
+	SeverityNone Severity = iota
+	// SeverityUser incorrect user input or usage
+	SeverityUser
+	// SeverityTransient temporary failure that may succeed on retry
+	SeverityTransient
+	// SeverityError environment, system or remote failure
+	SeverityError
+	// SeverityInternal bug in the program itself
+	SeverityInternal
+	// SeverityFatal process terminated by a signal
+	SeverityFatal
+)
+
+var severityNames = map[Severity]string{
+	SeverityNone:      "none",
+	SeverityUser:      "user",
+	SeverityTransient: "transient",
+	SeverityError:     "error",
+	SeverityInternal:  "internal",
+	SeverityFatal:     "fatal",
+}
+
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// Severity returns the severity of the code. CodeInfo.Severity is used when
+// set; otherwise it is derived: signals are fatal, user errors are user,
+// retriable codes are transient and everything else is error.
+func (c ExitCode) Severity() Severity {
+	info, ok := LookupCode(c)
+	switch {
+	case c == ExitCodeSuccess:
+		return SeverityNone
+	case ok && info.Severity != SeverityNone:
+		return info.Severity
+	case c.Category() == CategorySystemSignal:
+		return SeverityFatal
+	case info.UserError:
+		return SeverityUser
+	case info.Retriable:
+		return SeverityTransient
+	default:
+		return SeverityError
+	}
+}
+
+// ResolutionPolicy decides the code of an error that joins several errors
+// (errors.Join, fmt.Errorf with several %w, or any Unwrap() []error)
+type ResolutionPolicy int
+
+const (
+	// PolicyMostSevere resolves every branch and picks the code with the
+	// highest Severity; ties go to the earliest branch
+	PolicyMostSevere ResolutionPolicy = iota
+	// PolicyFirstMatch applies the resolver chain to the whole error, so the
+	// first match in a depth-first walk wins (argument order decides)
+	PolicyFirstMatch
+	// PolicyMostSpecific resolves every branch and prefers explicit ExitError
+	// codes over inferred ones, and inferred ones over the default;
+	// ties go to the earliest branch
+	PolicyMostSpecific
+)
+
+var resolutionPolicy atomic.Int32 // PolicyMostSevere
+
+// SetResolutionPolicy sets the policy ResolveExitCode uses for joined errors
+func SetResolutionPolicy(p ResolutionPolicy) {
+	resolutionPolicy.Store(int32(p))
+}
+
+// CurrentResolutionPolicy returns the policy used for joined errors
+func CurrentResolutionPolicy() ResolutionPolicy {
+	return ResolutionPolicy(resolutionPolicy.Load())
+}
+
+// explainTree resolves err applying the policy to the first joined error in
+// its chain. A link above the joined error that a resolver recognizes by
+// itself (an ExitError, a SignalError, ...) keeps its code.
+func explainTree(err error, p ResolutionPolicy) Resolution {
+	if p == PolicyFirstMatch {
+		return explainChain(err)
+	}
+	var links, branches []error
+	for cur := err; cur != nil; {
+		links = append(links, cur)
+		switch x := cur.(type) {
+		case interface{ Unwrap() []error }:
+			branches, cur = x.Unwrap(), nil
+		case interface{ Unwrap() error }:
+			cur = x.Unwrap()
+		default:
+			cur = nil
+		}
+	}
+	if branches == nil {
+		return explainChain(err)
+	}
+	for _, link := range links {
+		if res, ok := explainLink(link); ok {
+			return res
+		}
+	}
+	return explainBranches(err, branches, p)
+}
+
+// explainLink returns the first resolution that matches link itself rather
+// than an error it wraps
+func explainLink(link error) (Resolution, bool) {
+	for _, r := range Resolvers() {
+		if res, ok := explain(r, link); ok && sameError(res.Match, link) {
+			return res, true
+		}
+	}
+	return Resolution{}, false
+}
+
+// sameError reports whether a and b are the same error value, without
+// panicking on non-comparable types
+func sameError(a, b error) bool {
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	return ta != nil && ta == tb && ta.Comparable() && a == b
+}
+
+func explainBranches(err error, branches []error, p ResolutionPolicy) Resolution {
+	var best Resolution
+	bestRank, found := 0, false
+	for _, b := range branches {
+		if b == nil {
+			continue
+		}
+		res := explainTree(b, p)
+		if res.Code == ExitCodeSuccess {
+			continue
+		}
+		if rank := p.rank(res); !found || rank > bestRank {
+			best, bestRank, found = res, rank, true
+		}
+	}
+	if !found {
+		return explainChain(err)
+	}
+	return best
+}
+
+// rank orders candidate resolutions; higher wins. A branch no rule
+// recognizes ranks below every recognized branch.
+func (p ResolutionPolicy) rank(res Resolution) int {
+	if res.Rule == "default" {
+		return -1
+	}
+	if p == PolicyMostSpecific {
+		switch res.Rule {
+		case "ExitError":
+			return 1
+		default:
+			return 0
+		}
+	}
+	return int(res.Code.Severity())
+}
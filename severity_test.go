@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+)
+
+// withResolutionPolicy sets the policy for the duration of a test
+func withResolutionPolicy(t *testing.T, p ResolutionPolicy) {
+	t.Helper()
+	saved := CurrentResolutionPolicy()
+	SetResolutionPolicy(p)
+	t.Cleanup(func() { SetResolutionPolicy(saved) })
+}
+
+func TestExitCode_Severity(t *testing.T) {
+	tests := []struct {
+		code     ExitCode
+		expected Severity
+	}{
+		{ExitCodeSuccess, SeverityNone},
+		{ExitCodeValidation, SeverityUser},
+		{ExitCodeNotFound, SeverityUser},
+		{ExitCodeTempFail, SeverityTransient},
+		{ExitCodeIOError, SeverityTransient},
+		{ExitCodeAuthFailed, SeverityError},
+		{ExitCodeConflict, SeverityError},
+		{ExitCodeSoftware, SeverityInternal},
+		{ExitCodeErrorInternal, SeverityInternal},
+		{ExitCodeInterrupted, SeverityFatal},
+		{ExitCode(134), SeverityFatal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			if got := tt.code.Severity(); got != tt.expected {
+				t.Errorf("Severity() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveExitCode_JoinMostSevere(t *testing.T) {
+	withResolutionPolicy(t, PolicyMostSevere)
+	validation := ValidationError("row 3 invalid")
+	io := NewExitError(ExitCodeCantCreate, "cannot write output", nil)
+
+	for _, err := range []error{
+		errors.Join(validation, io),
+		errors.Join(io, validation),
+		fmt.Errorf("batch: %w", errors.Join(validation, io)),
+		fmt.Errorf("batch: %w; %w", validation, io),
+	} {
+		if got := ResolveExitCode(err); got != ExitCodeCantCreate {
+			t.Errorf("ResolveExitCode(%q) = %d, want %d", err, got, ExitCodeCantCreate)
+		}
+	}
+}
+
+func TestResolveExitCode_JoinFirstMatch(t *testing.T) {
+	withResolutionPolicy(t, PolicyFirstMatch)
+	err := errors.Join(ValidationError("invalid"), NewExitError(ExitCodeCantCreate, "write", nil))
+	if got := ResolveExitCode(err); got != ExitCodeValidation {
+		t.Errorf("ResolveExitCode() = %d, want %d", got, ExitCodeValidation)
+	}
+}
+
+func TestResolveExitCode_JoinMostSpecific(t *testing.T) {
+	withResolutionPolicy(t, PolicyMostSpecific)
+	err := errors.Join(errors.New("unknown"), ErrNotFound, ValidationError("invalid"))
+	if got := ResolveExitCode(err); got != ExitCodeValidation {
+		t.Errorf("ResolveExitCode() = %d, want %d (explicit ExitError)", got, ExitCodeValidation)
+	}
+
+	err = errors.Join(errors.New("unknown"), ErrNotFound)
+	if got := ResolveExitCode(err); got != ExitCodeNotFound {
+		t.Errorf("ResolveExitCode() = %d, want %d (inferred over default)", got, ExitCodeNotFound)
+	}
+}
+
+func TestResolveExitCode_ExitErrorAboveJoin(t *testing.T) {
+	withResolutionPolicy(t, PolicyMostSevere)
+	err := WithCode(errors.Join(ErrValidation, errors.New("unknown")), ExitCodeDataError)
+	if got := ResolveExitCode(err); got != ExitCodeDataError {
+		t.Errorf("ResolveExitCode() = %d, want explicit %d", got, ExitCodeDataError)
+	}
+}
+
+func TestExplainExitCode_Join(t *testing.T) {
+	withResolutionPolicy(t, PolicyMostSevere)
+	severe := NewExitError(ExitCodeSoftware, "bug", nil)
+	res := ExplainExitCode(errors.Join(ErrValidation, severe))
+	if res.Code != ExitCodeSoftware || res.Match != severe {
+		t.Errorf("ExplainExitCode() = %+v, want match on the most severe branch", res)
+	}
+}
+
+func TestResolveExitCode_JoinUnderRecognizedWrapper(t *testing.T) {
+	withResolutionPolicy(t, PolicyMostSevere)
+	cleanup := errors.New("remove temp dir: busy")
+	err := &SignalError{Signal: syscall.SIGTERM, Err: errors.Join(context.Canceled, cleanup)}
+	res := ExplainExitCode(err)
+	if res.Code != ExitCodeTerminated || res.Rule != "SignalError" {
+		t.Errorf("ExplainExitCode() = %v, want %d by SignalError", res, ExitCodeTerminated)
+	}
+
+	// An unrecognized wrapper still resolves the branches
+	err2 := fmt.Errorf("run: %w", errors.Join(ValidationError("bad"), NewExitError(ExitCodeCantCreate, "write", nil)))
+	if got := ResolveExitCode(err2); got != ExitCodeCantCreate {
+		t.Errorf("ResolveExitCode() = %d, want %d", got, ExitCodeCantCreate)
+	}
+}
+
+func TestResolveExitCode_JoinUnmatchedBranch(t *testing.T) {
+	for _, p := range []ResolutionPolicy{PolicyMostSevere, PolicyMostSpecific} {
+		withResolutionPolicy(t, p)
+		for _, err := range []error{
+			errors.Join(TempFailError("x"), errors.New("cleanup failed")),
+			errors.Join(errors.New("cleanup failed"), TempFailError("x")),
+		} {
+			if got := ResolveExitCode(err); got != ExitCodeTempFail {
+				t.Errorf("policy %d: ResolveExitCode(%q) = %d, want %d", p, err, got, ExitCodeTempFail)
+			}
+		}
+		// Every branch unmatched: the default code
+		if got := ResolveExitCode(errors.Join(errors.New("a"), errors.New("b"))); got != ExitCodeErrorInternal {
+			t.Errorf("policy %d: all unmatched = %d, want %d", p, got, ExitCodeErrorInternal)
+		}
+	}
+}
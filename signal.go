@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// signalBase is the offset shells add to a signal number to form the exit status
+const signalBase = 128
+
+// maxSignal is the highest signal number (SIGRTMAX on Linux)
+const maxSignal = 64
+
+// FromSignal returns the conventional 128+n exit code for a process killed by a signal
+func FromSignal(sig os.Signal) ExitCode {
+	s, ok := sig.(syscall.Signal)
+	if !ok || s <= 0 || int(s) > maxSignal {
+		return ExitCodeTerminated
+	}
+	return ExitCode(signalBase + int(s))
+}
+
+// Signal returns the signal encoded by a 128+n exit code
+func (c ExitCode) Signal() (os.Signal, bool) {
+	if c <= signalBase || c > signalBase+maxSignal {
+		return nil, false
+	}
+	return syscall.Signal(int(c) - signalBase), true
+}
+
+// SignalError records the signal that cancelled a context created by NotifyContext.
+// It is returned by context.Cause and resolves to the matching 128+n exit code.
+type SignalError struct {
+	Signal os.Signal
+	// Err is the wrapped error; nil means context.Canceled
+	Err error
+}
+
+func (e *SignalError) Error() string {
+	return fmt.Sprintf("%v (signal: %v)", e.Unwrap(), e.Signal)
+}
+
+// Unwrap returns the wrapped error, so errors.Is(err, context.Canceled) keeps working
+func (e *SignalError) Unwrap() error {
+	if e.Err == nil {
+		return context.Canceled
+	}
+	return e.Err
+}
+
+// NotifyContext returns a copy of parent that is cancelled when one of the
+// signals arrives. Unlike signal.NotifyContext, the received signal is
+// recorded as a *SignalError available through context.Cause. The signals
+// are unregistered once the first one arrives, so a second one is handled
+// by the default action (usually terminating the process).
+// The returned stop function unregisters the signals and releases resources.
+func NotifyContext(parent context.Context, signals ...os.Signal) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	go func() {
+		select {
+		case sig := <-ch:
+			// Restore the default action so a second signal terminates
+			// a command that ignores the cancelled context
+			signal.Stop(ch)
+			cancel(&SignalError{Signal: sig})
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(ch)
+		cancel(nil)
+	}
+}
+
+// resolveSignal maps a signal recorded by NotifyContext to 128+n
+func resolveSignal(err error) (Resolution, bool) {
+	var sigErr *SignalError
+	if errors.As(err, &sigErr) {
+		return Resolution{Code: FromSignal(sigErr.Signal), Rule: "SignalError", Match: sigErr}, true
+	}
+	return Resolution{}, false
+}
+
+// signalCause returns the signal that cancelled ctx, if any
+func signalCause(ctx context.Context) (*SignalError, bool) {
+	se, ok := context.Cause(ctx).(*SignalError)
+	return se, ok
+}
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFromSignal(t *testing.T) {
+	tests := []struct {
+		sig      os.Signal
+		expected ExitCode
+	}{
+		{syscall.SIGHUP, ExitCodeHangup},
+		{os.Interrupt, ExitCodeInterrupted},
+		{syscall.SIGQUIT, ExitCodeQuit},
+		{os.Kill, ExitCodeKilled},
+		{syscall.SIGPIPE, ExitCodeBrokenPipe},
+		{syscall.SIGTERM, ExitCodeTerminated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sig.String(), func(t *testing.T) {
+			if got := FromSignal(tt.sig); got != tt.expected {
+				t.Errorf("FromSignal(%v) = %d, want %d", tt.sig, got, tt.expected)
+			}
+			sig, ok := tt.expected.Signal()
+			if !ok || sig != tt.sig {
+				t.Errorf("ExitCode(%d).Signal() = %v, %v, want %v", tt.expected, sig, ok, tt.sig)
+			}
+		})
+	}
+}
+
+func TestExitCode_Signal_OutOfRange(t *testing.T) {
+	for _, code := range []ExitCode{ExitCodeSuccess, ExitCodeError, ExitCodeConfig, 128, 999} {
+		if sig, ok := code.Signal(); ok {
+			t.Errorf("ExitCode(%d).Signal() = %v, want none", code, sig)
+		}
+	}
+}
+
+func TestExitCode_String_Signal(t *testing.T) {
+	if got := ExitCode(134).String(); got != "Terminated by signal 6 (aborted)" {
+		t.Errorf("ExitCode(134).String() = %q", got)
+	}
+	if got := ExitCode(134).Category(); got != CategorySystemSignal {
+		t.Errorf("ExitCode(134).Category() = %v, want %v", got, CategorySystemSignal)
+	}
+}
+
+func TestResolveExitCode_SignalError(t *testing.T) {
+	err := &SignalError{Signal: syscall.SIGHUP}
+	if !errors.Is(err, context.Canceled) {
+		t.Error("SignalError should match context.Canceled")
+	}
+	if got := ResolveExitCode(err); got != ExitCodeHangup {
+		t.Errorf("ResolveExitCode() = %d, want %d", got, ExitCodeHangup)
+	}
+}
+
+func TestNotifyContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending signals to self is not supported on windows")
+	}
+	ctx, stop := NotifyContext(context.Background(), syscall.SIGHUP)
+	defer stop()
+
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Signal(syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context was not cancelled by signal")
+	}
+	if got := ResolveExitCode(context.Cause(ctx)); got != ExitCodeHangup {
+		t.Errorf("ResolveExitCode(Cause) = %d, want %d", got, ExitCodeHangup)
+	}
+}
+
+func TestNotifyContext_Stop(t *testing.T) {
+	ctx, stop := NotifyContext(context.Background(), syscall.SIGHUP)
+	stop()
+	if !errors.Is(context.Cause(ctx), context.Canceled) {
+		t.Errorf("Cause = %v, want context.Canceled", context.Cause(ctx))
+	}
+	if _, ok := signalCause(ctx); ok {
+		t.Error("stopped context should not record a signal")
+	}
+}
+
+func TestNotifyContext_SecondSignalTerminates(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending signals is not supported on windows")
+	}
+	if os.Getenv("CLI_TEST_SIGNAL_CHILD") == "1" {
+		// Child: a command that ignores its cancelled context
+		ctx, stop := NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		os.Stdout.WriteString("ready\n")
+		<-ctx.Done()
+		os.Stdout.WriteString("cancelled\n")
+		time.Sleep(10 * time.Second)
+		os.Exit(0)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestNotifyContext_SecondSignalTerminates$")
+	cmd.Env = append(os.Environ(), "CLI_TEST_SIGNAL_CHILD=1")
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	lines := bufio.NewScanner(out)
+	expect := func(want string) {
+		t.Helper()
+		if !lines.Scan() || lines.Text() != want {
+			_ = cmd.Process.Kill()
+			t.Fatalf("child output = %q, want %q", lines.Text(), want)
+		}
+	}
+	interrupt := func() {
+		t.Helper()
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expect("ready")
+	interrupt()
+	expect("cancelled")
+	interrupt()
+
+	err = cmd.Wait()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("child exited with %v, want killed by the second signal", err)
+	}
+	if ws, ok := exitErr.Sys().(syscall.WaitStatus); !ok || !ws.Signaled() || ws.Signal() != syscall.SIGINT {
+		t.Errorf("child status = %v, want killed by SIGINT", exitErr)
+	}
+}
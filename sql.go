@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+)
+
+// sqlStateError is implemented by driver errors carrying a SQLSTATE, such as
+// *pgconn.PgError and *pq.Error
+type sqlStateError interface {
+	error
+	SQLState() string
+}
+
+// SQLSTATE codes and classes (first two characters) mapped to exit codes.
+// Codes are checked before classes.
+var (
+	sqlStateCodes = map[string]ExitCode{
+		"40001": ExitCodeTempFail,    // serialization_failure
+		"40P01": ExitCodeTempFail,    // deadlock_detected
+		"42501": ExitCodeForbidden,   // insufficient_privilege
+		"57P01": ExitCodeUnavailable, // admin_shutdown
+		"57P02": ExitCodeUnavailable, // crash_shutdown
+		"57P03": ExitCodeUnavailable, // cannot_connect_now
+	}
+	sqlStateClasses = map[string]ExitCode{
+		"08": ExitCodeUnavailable,   // connection exception
+		"22": ExitCodeDataError,     // data exception
+		"23": ExitCodeConflict,      // integrity constraint violation
+		"28": ExitCodeAuthFailed,    // invalid authorization specification
+		"42": ExitCodeDataError,     // syntax error or access rule violation
+		"53": ExitCodeQuotaExceeded, // insufficient resources
+	}
+)
+
+// resolveSQL maps database/sql errors and driver errors exposing SQLState()
+func resolveSQL(err error) (Resolution, bool) {
+	var code ExitCode
+	match := findLink(err, func(e error) bool {
+		se, ok := e.(sqlStateError)
+		if !ok {
+			return false
+		}
+		c, ok := fromSQLState(se.SQLState())
+		code = c
+		return ok
+	})
+	if match != nil {
+		return Resolution{Code: code, Rule: "SQLSTATE " + match.(sqlStateError).SQLState(), Match: match}, true
+	}
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return matchSentinel(err, sql.ErrNoRows, ExitCodeNotFound, "sql.ErrNoRows"), true
+	case errors.Is(err, sql.ErrConnDone):
+		return matchSentinel(err, sql.ErrConnDone, ExitCodeUnavailable, "sql.ErrConnDone"), true
+	case errors.Is(err, driver.ErrBadConn):
+		return matchSentinel(err, driver.ErrBadConn, ExitCodeUnavailable, "driver.ErrBadConn"), true
+	}
+	return Resolution{}, false
+}
+
+// fromSQLState maps a SQLSTATE code to an exit code
+func fromSQLState(state string) (ExitCode, bool) {
+	if code, ok := sqlStateCodes[state]; ok {
+		return code, true
+	}
+	if len(state) != 5 {
+		return 0, false
+	}
+	code, ok := sqlStateClasses[state[:2]]
+	return code, ok
+}
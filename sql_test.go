@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+)
+
+// pgError mimics *pgconn.PgError
+type pgError struct{ code, message string }
+
+func (e *pgError) Error() string    { return fmt.Sprintf("ERROR: %s (SQLSTATE %s)", e.message, e.code) }
+func (e *pgError) SQLState() string { return e.code }
+
+func TestResolveExitCode_SQL(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ExitCode
+		rule string
+	}{
+		{"no_rows", fmt.Errorf("user 42: %w", sql.ErrNoRows), ExitCodeNotFound, "sql.ErrNoRows"},
+		{"conn_done", sql.ErrConnDone, ExitCodeUnavailable, "sql.ErrConnDone"},
+		{"bad_conn", fmt.Errorf("query: %w", driver.ErrBadConn), ExitCodeUnavailable, "driver.ErrBadConn"},
+		{"unique_violation", &pgError{"23505", "duplicate key value"}, ExitCodeConflict, "SQLSTATE 23505"},
+		{"auth", &pgError{"28P01", "password authentication failed"}, ExitCodeAuthFailed, "SQLSTATE 28P01"},
+		{"serialization", fmt.Errorf("migrate: %w", &pgError{"40001", "could not serialize access"}), ExitCodeTempFail, "SQLSTATE 40001"},
+		{"disk_full", &pgError{"53100", "could not extend file"}, ExitCodeQuotaExceeded, "SQLSTATE 53100"},
+		{"syntax", &pgError{"42601", "syntax error"}, ExitCodeDataError, "SQLSTATE 42601"},
+		{"privilege", &pgError{"42501", "permission denied for table users"}, ExitCodeForbidden, "SQLSTATE 42501"},
+		{"connection", &pgError{"08006", "connection failure"}, ExitCodeUnavailable, "SQLSTATE 08006"},
+		{"unmapped", &pgError{"P0001", "raise_exception"}, ExitCodeErrorInternal, "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := ExplainExitCode(tt.err)
+			if res.Code != tt.want || res.Rule != tt.rule {
+				t.Errorf("ExplainExitCode(%v) = %d by %q, want %d by %q", tt.err, res.Code, res.Rule, tt.want, tt.rule)
+			}
+		})
+	}
+}
+
+// badConnError is a driver error that is both a bad connection and carries a SQLSTATE
+type badConnError struct{ pgError }
+
+func (e *badConnError) Is(target error) bool { return target == driver.ErrBadConn }
+
+func TestResolveExitCode_SQLStateBeforeSentinel(t *testing.T) {
+	err := &badConnError{pgError{"28000", "invalid authorization"}}
+	if got := ResolveExitCode(err); got != ExitCodeAuthFailed {
+		t.Errorf("ResolveExitCode() = %d, want %d", got, ExitCodeAuthFailed)
+	}
+}
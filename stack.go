@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// maxStackDepth limits the number of frames captured per error
+const maxStackDepth = 32
+
+var (
+	captureStacks atomic.Bool
+	verboseJSON   atomic.Bool
+)
+
+// SetCaptureStacks enables or disables stack capture in NewExitError, Newf,
+// WithCode and the helper constructors. Capture records program counters
+// only; symbols are resolved when the stack is printed. Disabled by default.
+func SetCaptureStacks(enabled bool) {
+	captureStacks.Store(enabled)
+}
+
+// CaptureStacks reports whether stacks are captured for every new ExitError
+func CaptureStacks() bool {
+	return captureStacks.Load()
+}
+
+// SetVerboseJSON controls whether ExitError.MarshalJSON includes the
+// captured stack as the "stack" member. Disabled by default.
+func SetVerboseJSON(enabled bool) {
+	verboseJSON.Store(enabled)
+}
+
+// StackTrace is the call stack captured when an ExitError was created,
+// innermost frame first
+type StackTrace []uintptr
+
+// Frames resolves the program counters to frames
+func (s StackTrace) Frames() []runtime.Frame {
+	if len(s) == 0 {
+		return nil
+	}
+	frames := make([]runtime.Frame, 0, len(s))
+	it := runtime.CallersFrames(s)
+	for {
+		f, more := it.Next()
+		frames = append(frames, f)
+		if !more {
+			return frames
+		}
+	}
+}
+
+// String formats the stack like a goroutine dump: one "function" line and
+// one indented "file:line" line per frame
+func (s StackTrace) String() string {
+	var b strings.Builder
+	s.writeTo(&b)
+	return b.String()
+}
+
+func (s StackTrace) writeTo(w io.Writer) {
+	for _, f := range s.Frames() {
+		fmt.Fprintf(w, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+	}
+}
+
+// stackFrameJSON is a frame in the ExitError wire format
+type stackFrameJSON struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+func (s StackTrace) json() []stackFrameJSON {
+	frames := s.Frames()
+	if len(frames) == 0 {
+		return nil
+	}
+	out := make([]stackFrameJSON, len(frames))
+	for i, f := range frames {
+		out[i] = stackFrameJSON{Function: f.Function, File: f.File, Line: f.Line}
+	}
+	return out
+}
+
+// callers captures the stack of the caller skip levels above the function
+// calling callers (skip 0 is that function's caller)
+func callers(skip int) StackTrace {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(skip+3, pcs[:])
+	return append(StackTrace(nil), pcs[:n]...)
+}
+
+// StackTrace returns the stack captured when the error was created, or nil
+// if capture was disabled (see SetCaptureStacks and WithStack)
+func (e *ExitError) StackTrace() StackTrace {
+	if e == nil {
+		return nil
+	}
+	return e.stack
+}
+
+// WithStack captures the caller's stack on this error, whatever the global
+// setting, and returns the error for chaining
+func (e *ExitError) WithStack() *ExitError {
+	if e == nil {
+		return nil
+	}
+	e.stack = callers(0)
+	return e
+}
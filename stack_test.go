@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func withCaptureStacks(t *testing.T, enabled bool) {
+	t.Helper()
+	prev := CaptureStacks()
+	SetCaptureStacks(enabled)
+	t.Cleanup(func() { SetCaptureStacks(prev) })
+}
+
+func topFunction(t *testing.T, e *ExitError) string {
+	t.Helper()
+	frames := e.StackTrace().Frames()
+	if len(frames) == 0 {
+		t.Fatal("no stack captured")
+	}
+	return frames[0].Function
+}
+
+func TestStackCapture_Disabled(t *testing.T) {
+	withCaptureStacks(t, false)
+	if st := NewExitError(ExitCodeConfig, "bad", nil).StackTrace(); st != nil {
+		t.Errorf("StackTrace() = %v, want nil when disabled", st)
+	}
+	if st := (*ExitError)(nil).StackTrace(); st != nil {
+		t.Errorf("StackTrace() on nil = %v, want nil", st)
+	}
+}
+
+func TestStackCapture_Constructors(t *testing.T) {
+	withCaptureStacks(t, true)
+	const want = "github.com/hadean-go/cli.TestStackCapture_Constructors"
+
+	for name, e := range map[string]*ExitError{
+		"NewExitError": NewExitError(ExitCodeConfig, "bad", nil),
+		"Newf":         Newf(ExitCodeConfig, "bad %d", 1),
+		"WithCode":     WithCode(ErrIO, ExitCodeIOError),
+		"ConfigError":  ConfigError("bad"),
+		"NotFound":     NotFoundError("volume"),
+	} {
+		if got := topFunction(t, e); got != want {
+			t.Errorf("%s: top frame = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestWithStack(t *testing.T) {
+	withCaptureStacks(t, false)
+	e := NewExitError(ExitCodeConfig, "bad", nil).WithStack()
+	if got := topFunction(t, e); got != "github.com/hadean-go/cli.TestWithStack" {
+		t.Errorf("top frame = %s", got)
+	}
+}
+
+func TestExitError_FormatStack(t *testing.T) {
+	withCaptureStacks(t, true)
+	e := ConfigError("missing endpoint")
+
+	if got := fmt.Sprintf("%v", e); got != "missing endpoint" {
+		t.Errorf("%%v = %q, want message only", got)
+	}
+	got := fmt.Sprintf("%+v", e)
+	if !strings.Contains(got, "\nstack:\ngithub.com/hadean-go/cli.TestExitError_FormatStack\n\t") {
+		t.Errorf("%%+v = %q, want stack", got)
+	}
+	if !strings.Contains(got, "stack_test.go:") {
+		t.Errorf("%%+v missing file:line: %q", got)
+	}
+}
+
+func TestExitError_JSONStack(t *testing.T) {
+	withCaptureStacks(t, true)
+	e := ConfigError("missing endpoint")
+
+	data, _ := json.Marshal(e)
+	if strings.Contains(string(data), `"stack"`) {
+		t.Errorf("stack written without verbose JSON: %s", data)
+	}
+
+	SetVerboseJSON(true)
+	t.Cleanup(func() { SetVerboseJSON(false) })
+	data, _ = json.Marshal(e)
+	var w struct {
+		Stack []struct {
+			Function string `json:"function"`
+			File     string `json:"file"`
+			Line     int    `json:"line"`
+		} `json:"stack"`
+	}
+	if err := json.Unmarshal(data, &w); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.Stack) == 0 || w.Stack[0].Function != "github.com/hadean-go/cli.TestExitError_JSONStack" || w.Stack[0].Line == 0 {
+		t.Errorf("stack = %+v", w.Stack)
+	}
+
+	// Decoding ignores the stack
+	var decoded ExitError
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.StackTrace() != nil {
+		t.Errorf("decoded stack = %v, err = %v", decoded.StackTrace(), err)
+	}
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// RetryTransport is an http.RoundTripper that retries failed requests.
+// Each response is classified with FromHTTPStatus and each transport error
+// with ResolveExitCode; only retriable codes are retried (see
+// RetryPolicy.Retriable), honouring Retry-After. A request is replayed only
+// when it is idempotent (GET, HEAD, OPTIONS, TRACE, PUT, DELETE or carrying
+// an Idempotency-Key header) and its body can be rewound (no body or
+// Request.GetBody set).
+//
+// Responses with statuses below 400 or non-retriable statuses are returned
+// unchanged, as are responses asking to retry after more than
+// Policy.MaxRetryAfter.
+// When retries are exhausted RoundTrip returns an *ExitError built by
+// FromHTTPResponse or from the transport error, wrapped as described in Retry.
+type RetryTransport struct {
+	// Base performs the requests; nil means http.DefaultTransport
+	Base http.RoundTripper
+	// Policy controls attempts, backoff, the longest honoured Retry-After
+	// and which codes are retried
+	Policy RetryPolicy
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	policy := t.Policy
+	if !replayable(req) {
+		policy.Retriable = func(ExitCode) bool { return false }
+	}
+	p := policy.withDefaults()
+
+	var resp *http.Response
+	attempt := 0
+	err := Retry(req.Context(), policy, func(ctx context.Context) error {
+		attempt++
+		r, err := rewind(req, attempt)
+		if err != nil {
+			return WithCode(err, ExitCodeSoftware)
+		}
+		res, err := t.base().RoundTrip(r)
+		if err != nil {
+			return err
+		}
+		if res.StatusCode < 400 || !p.retriable(FromHTTPStatus(res.StatusCode)) {
+			resp = res
+			return nil
+		}
+		statusErr := FromHTTPResponse(res)
+		if d, hinted := RetryAfter(statusErr); statusErr == nil || hinted && d > p.MaxRetryAfter {
+			resp = res
+			return nil
+		}
+		drain(res)
+		return statusErr
+	})
+	if err != nil {
+		if !errors.As(err, new(*ExitError)) {
+			err = WithCode(err, ResolveExitCode(err))
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (t *RetryTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// replayable reports whether req may safely be sent more than once
+func replayable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	_, hasKey := req.Header["Idempotency-Key"]
+	_, hasXKey := req.Header["X-Idempotency-Key"]
+	return hasKey || hasXKey
+}
+
+// rewind returns the request to send for the given (1-based) attempt
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 1 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
+
+// drain discards the rest of a response body so the connection can be reused
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxProblemBody))
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first n requests with status, then answers 200 with the request body
+func flakyServer(t *testing.T, n int32, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if calls.Add(1) <= n {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func retryClient() *http.Client {
+	return &http.Client{Transport: &RetryTransport{
+		Policy: RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond},
+	}}
+}
+
+func TestRetryTransport_RetriesIdempotent(t *testing.T) {
+	srv, calls := flakyServer(t, 2, http.StatusServiceUnavailable)
+
+	req, _ := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader("payload"))
+	resp, err := retryClient().Do(req)
+	if err != nil {
+		t.Fatalf("Do() error: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != 200 || string(body) != "payload" {
+		t.Errorf("response = %d %q, want 200 with rewound body", resp.StatusCode, body)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3", calls.Load())
+	}
+}
+
+func TestRetryTransport_Exhausted(t *testing.T) {
+	srv, calls := flakyServer(t, 10, http.StatusTooManyRequests)
+
+	_, err := retryClient().Get(srv.URL)
+	if err == nil {
+		t.Fatal("Get() should fail")
+	}
+	if got := ResolveExitCode(err); got != ExitCodeRateLimit {
+		t.Errorf("ResolveExitCode() = %d, want %d", got, ExitCodeRateLimit)
+	}
+	var re *RetryError
+	if !errors.As(err, &re) || re.Attempts != 3 {
+		t.Errorf("RetryError = %+v, want 3 attempts", re)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3", calls.Load())
+	}
+}
+
+func TestRetryTransport_NonRetriableStatus(t *testing.T) {
+	srv, calls := flakyServer(t, 10, http.StatusNotFound)
+
+	resp, err := retryClient().Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound || calls.Load() != 1 {
+		t.Errorf("status = %d, calls = %d; want 404 after one call", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestRetryTransport_PostNotReplayed(t *testing.T) {
+	srv, calls := flakyServer(t, 10, http.StatusServiceUnavailable)
+
+	resp, err := retryClient().Post(srv.URL, "text/plain", strings.NewReader("x"))
+	if err != nil {
+		t.Fatalf("Post() error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Errorf("status = %d, calls = %d; want 503 after one call", resp.StatusCode, calls.Load())
+	}
+
+	// An Idempotency-Key makes POST replayable
+	calls.Store(8)
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("x"))
+	req.Header.Set("Idempotency-Key", "k-1")
+	resp, err = retryClient().Do(req)
+	if err != nil {
+		t.Fatalf("Do() error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 200 || calls.Load() != 11 {
+		t.Errorf("status = %d, calls = %d; want 200 after retries", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestRetryTransport_TransportError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	_, err := retryClient().Get(url)
+	var ee *ExitError
+	if !errors.As(err, &ee) {
+		t.Fatalf("Get() = %v, want ExitError", err)
+	}
+	if ee.Code != ExitCodeUnavailable {
+		t.Errorf("code = %d, want %d", ee.Code, ExitCodeUnavailable)
+	}
+}
+
+func TestReplayable(t *testing.T) {
+	get, _ := http.NewRequest(http.MethodGet, "http://x", nil)
+	streamed, _ := http.NewRequest(http.MethodPut, "http://x", io.NopCloser(strings.NewReader("x")))
+	post, _ := http.NewRequest(http.MethodPost, "http://x", nil)
+
+	if !replayable(get) {
+		t.Error("GET without body should be replayable")
+	}
+	if replayable(streamed) {
+		t.Error("PUT with non-rewindable body should not be replayable")
+	}
+	if replayable(post) {
+		t.Error("POST without Idempotency-Key should not be replayable")
+	}
+}
+
+func TestRetryTransport_SuccessStatusNeverRetried(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &RetryTransport{Policy: RetryPolicy{
+		MaxAttempts: 3,
+		Retriable:   func(ExitCode) bool { return true },
+	}}}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("status = %d, want 304", resp.StatusCode)
+	}
+}
+
+func TestRetryTransport_LongRetryAfterNotWaited(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	start := time.Now()
+	resp, err := retryClient().Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Errorf("status = %d, calls = %d; want 503 after one call", resp.StatusCode, calls.Load())
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("RoundTrip waited %v", time.Since(start))
+	}
+}